@@ -0,0 +1,243 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Chaine de possession des lots d'or
+//
+// RecordTransaction se contentait d'enregistrer une transaction isolee,
+// impossible a relier aux mouvements precedents ou suivants du meme lot.
+// ParentTxIDs et LotID relient chaque transaction a celles dont elle
+// consomme l'or, transformant le registre en une chaine de bilan massique
+// verifiable plutot qu'un sac de transactions independantes.
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// massBalanceEpsilon absorbe les arrondis flottants lors de la verification
+// que la somme des allocations par parent correspond exactement au
+// QuantityGrams de la transaction enfant.
+const massBalanceEpsilon = 1e-6
+
+// LotLineage est le graphe de provenance complet retrouve pour un lot : les
+// transactions portant le LotID d'origine, ainsi que toute transaction
+// reliee par ascendance ou descendance parent~enfant (utile si le lot a ete
+// scinde ou fusionne sous un LotID different en cours de chaine).
+type LotLineage struct {
+	LotID        string             `json:"lotId"`
+	Transactions []*GoldTransaction `json:"transactions"`
+}
+
+// validateAndDebitParents verifie, pour chaque transaction parente
+// declaree, qu'elle existe, que l'entite emettrice de l'enfant correspond a
+// l'entite destinataire du parent, et que l'allocation qui lui est assignee
+// ne depasse pas son solde restant. parentAllocations donne, dans le meme
+// ordre que parentTxIDs, la quantite precisement puisee dans chaque parent ;
+// leur somme doit correspondre a quantityGrams, sans quoi une transaction
+// avec plusieurs parents debiterait la totalite de quantityGrams de chacun
+// d'eux et fabriquerait de l'or. Elle retourne les transactions parentes
+// avec leur RemainingGrams debite, a charge de l'appelant de les persister -
+// aucune ecriture n'est effectuee ici pour que RecordTransaction reste seul
+// maitre de l'ordre des ecritures sur le ledger.
+func (gc *GoldTrackContract) validateAndDebitParents(
+	ctx contractapi.TransactionContextInterface,
+	fromEntity string,
+	quantityGrams float64,
+	parentTxIDs []string,
+	parentAllocations []float64,
+) ([]*GoldTransaction, error) {
+	if len(parentAllocations) != len(parentTxIDs) {
+		return nil, fmt.Errorf("le nombre d'allocations (%d) ne correspond pas au nombre de transactions parentes (%d)", len(parentAllocations), len(parentTxIDs))
+	}
+
+	seenParents := make(map[string]bool, len(parentTxIDs))
+	for _, parentID := range parentTxIDs {
+		if seenParents[parentID] {
+			return nil, fmt.Errorf("la transaction parente %s est listee plusieurs fois dans parentTxIDs", parentID)
+		}
+		seenParents[parentID] = true
+	}
+
+	parents := make([]*GoldTransaction, 0, len(parentTxIDs))
+	var allocatedTotal float64
+
+	for i, parentID := range parentTxIDs {
+		allocation := parentAllocations[i]
+		if allocation <= 0 {
+			return nil, fmt.Errorf("l'allocation pour la transaction parente %s doit etre positive: %f", parentID, allocation)
+		}
+
+		parent, err := gc.GetTransaction(ctx, parentID)
+		if err != nil {
+			return nil, fmt.Errorf("transaction parente %s introuvable: %w", parentID, err)
+		}
+
+		if parent.ToEntity != fromEntity {
+			return nil, fmt.Errorf("l'entite emettrice %s ne correspond pas a l'entite destinataire %s de la transaction parente %s", fromEntity, parent.ToEntity, parentID)
+		}
+
+		if allocation > parent.RemainingGrams {
+			return nil, fmt.Errorf("l'allocation %f depasse le solde restant %f de la transaction parente %s", allocation, parent.RemainingGrams, parentID)
+		}
+
+		parent.RemainingGrams -= allocation
+		allocatedTotal += allocation
+		parents = append(parents, parent)
+	}
+
+	if len(parentTxIDs) > 0 && math.Abs(allocatedTotal-quantityGrams) > massBalanceEpsilon {
+		return nil, fmt.Errorf("la somme des allocations parentes (%f) ne correspond pas a la quantite de la transaction (%f)", allocatedTotal, quantityGrams)
+	}
+
+	return parents, nil
+}
+
+// indexCustody ecrit l'index lot~id et, pour chaque transaction parente,
+// l'index parent~child reliant le parent a cette nouvelle transaction.
+func indexCustody(
+	ctx contractapi.TransactionContextInterface,
+	id string,
+	lotID string,
+	parentTxIDs []string,
+) error {
+	lotKey, err := ctx.GetStub().CreateCompositeKey("lot~id", []string{lotID, id})
+	if err != nil {
+		return fmt.Errorf("echec creation cle composite lot: %w", err)
+	}
+	if err := ctx.GetStub().PutState(lotKey, []byte{0x00}); err != nil {
+		return fmt.Errorf("echec ecriture cle composite lot: %w", err)
+	}
+
+	for _, parentID := range parentTxIDs {
+		parentChildKey, err := ctx.GetStub().CreateCompositeKey("parent~child", []string{parentID, id})
+		if err != nil {
+			return fmt.Errorf("echec creation cle composite parent~child: %w", err)
+		}
+		if err := ctx.GetStub().PutState(parentChildKey, []byte{0x00}); err != nil {
+			return fmt.Errorf("echec ecriture cle composite parent~child: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// GetLotLineage retrouve, par parcours en largeur, le graphe de provenance
+// complet d'un lot : on part des transactions indexees sous ce LotID, puis
+// on remonte vers leurs parents (deja references dans ParentTxIDs) et on
+// descend vers leurs enfants (via l'index parent~child) jusqu'a epuisement.
+// ---------------------------------------------------------------------------
+func (gc *GoldTrackContract) GetLotLineage(
+	ctx contractapi.TransactionContextInterface,
+	lotID string,
+) (*LotLineage, error) {
+	visited := make(map[string]bool)
+	var queue []string
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("lot~id", []string{lotID})
+	if err != nil {
+		return nil, fmt.Errorf("echec requete lot %s: %w", lotID, err)
+	}
+	for iterator.HasNext() {
+		responseRange, err := iterator.Next()
+		if err != nil {
+			iterator.Close()
+			return nil, fmt.Errorf("echec iteration lot: %w", err)
+		}
+		_, parts, err := ctx.GetStub().SplitCompositeKey(responseRange.Key)
+		if err != nil || len(parts) < 2 {
+			continue
+		}
+		queue = append(queue, parts[1])
+	}
+	iterator.Close()
+
+	var transactions []*GoldTransaction
+
+	for len(queue) > 0 {
+		txID := queue[0]
+		queue = queue[1:]
+
+		if visited[txID] {
+			continue
+		}
+		visited[txID] = true
+
+		tx, err := gc.GetTransaction(ctx, txID)
+		if err != nil {
+			continue
+		}
+		transactions = append(transactions, tx)
+
+		// Remonter vers les parents
+		queue = append(queue, tx.ParentTxIDs...)
+
+		// Descendre vers les enfants
+		childIterator, err := ctx.GetStub().GetStateByPartialCompositeKey("parent~child", []string{txID})
+		if err != nil {
+			return nil, fmt.Errorf("echec requete enfants de %s: %w", txID, err)
+		}
+		for childIterator.HasNext() {
+			responseRange, err := childIterator.Next()
+			if err != nil {
+				childIterator.Close()
+				return nil, fmt.Errorf("echec iteration enfants: %w", err)
+			}
+			_, parts, err := ctx.GetStub().SplitCompositeKey(responseRange.Key)
+			if err != nil || len(parts) < 2 {
+				continue
+			}
+			queue = append(queue, parts[1])
+		}
+		childIterator.Close()
+	}
+
+	return &LotLineage{LotID: lotID, Transactions: transactions}, nil
+}
+
+// ---------------------------------------------------------------------------
+// FlagTransactionSuspicious marque une transaction comme suspecte et emet un
+// evenement pour qu'un service d'alerte hors-chaine puisse reagir sans
+// scruter le ledger.
+// ---------------------------------------------------------------------------
+func (gc *GoldTrackContract) FlagTransactionSuspicious(
+	ctx contractapi.TransactionContextInterface,
+	id string,
+	reason string,
+	flaggedBy string,
+) error {
+	tx, err := gc.GetTransaction(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	tx.Flagged = true
+	tx.FlagReason = reason
+
+	txJSON, err := json.Marshal(tx)
+	if err != nil {
+		return fmt.Errorf("echec serialisation transaction: %w", err)
+	}
+
+	if err := ctx.GetStub().PutState(id, txJSON); err != nil {
+		return fmt.Errorf("echec ecriture state: %w", err)
+	}
+
+	eventPayload, err := json.Marshal(map[string]string{
+		"txId":      id,
+		"reason":    reason,
+		"flaggedBy": flaggedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("echec serialisation evenement: %w", err)
+	}
+
+	if err := ctx.GetStub().SetEvent("GoldTransactionFlagged", eventPayload); err != nil {
+		return fmt.Errorf("echec emission evenement: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Chain of Custody Unit Tests
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLotLineage_FollowsParentsAndChildren(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	gc := new(GoldTrackContract)
+
+	lotID := "LOT-001"
+	root := GoldTransaction{ID: "TX-ROOT", LotID: lotID}
+	child := GoldTransaction{ID: "TX-CHILD", LotID: lotID, ParentTxIDs: []string{"TX-ROOT"}}
+	rootJSON, _ := json.Marshal(root)
+	childJSON, _ := json.Marshal(child)
+
+	lotIterator := &mockStateQueryIterator{results: []*queryresultKV{
+		{Key: "lot~id\x00LOT-001\x00TX-ROOT\x00"},
+	}}
+	stub.On("GetStateByPartialCompositeKey", "lot~id", []string{lotID}).Return(lotIterator, nil).Once()
+	stub.On("SplitCompositeKey", "lot~id\x00LOT-001\x00TX-ROOT\x00").Return("lot~id", []string{lotID, "TX-ROOT"}, nil).Once()
+
+	stub.On("GetState", "TX-ROOT").Return(rootJSON, nil).Once()
+	rootChildren := &mockStateQueryIterator{results: []*queryresultKV{
+		{Key: "parent~child\x00TX-ROOT\x00TX-CHILD\x00"},
+	}}
+	stub.On("GetStateByPartialCompositeKey", "parent~child", []string{"TX-ROOT"}).Return(rootChildren, nil).Once()
+	stub.On("SplitCompositeKey", "parent~child\x00TX-ROOT\x00TX-CHILD\x00").Return("parent~child", []string{"TX-ROOT", "TX-CHILD"}, nil).Once()
+
+	stub.On("GetState", "TX-CHILD").Return(childJSON, nil).Once()
+	childChildren := &mockStateQueryIterator{}
+	stub.On("GetStateByPartialCompositeKey", "parent~child", []string{"TX-CHILD"}).Return(childChildren, nil).Once()
+
+	lineage, err := gc.GetLotLineage(ctx, lotID)
+	assert.NoError(t, err)
+	assert.Equal(t, lotID, lineage.LotID)
+	assert.Len(t, lineage.Transactions, 2)
+
+	stub.AssertExpectations(t)
+}
+
+func TestValidateAndDebitParents_RejectsDuplicateParent(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	gc := new(GoldTrackContract)
+
+	_, err := gc.validateAndDebitParents(ctx, "COOP-A", 16.0, []string{"P1", "P1"}, []float64{8.0, 8.0})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "plusieurs fois")
+
+	stub.AssertExpectations(t)
+}
+
+func TestValidateAndDebitParents_SplitsAcrossParents(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	gc := new(GoldTrackContract)
+
+	parent1 := GoldTransaction{ID: "P1", ToEntity: "COOP-A", RemainingGrams: 10.0}
+	parent2 := GoldTransaction{ID: "P2", ToEntity: "COOP-A", RemainingGrams: 6.0}
+	parent1JSON, _ := json.Marshal(parent1)
+	parent2JSON, _ := json.Marshal(parent2)
+
+	stub.On("GetState", "P1").Return(parent1JSON, nil).Once()
+	stub.On("GetState", "P2").Return(parent2JSON, nil).Once()
+
+	parents, err := gc.validateAndDebitParents(ctx, "COOP-A", 16.0, []string{"P1", "P2"}, []float64{10.0, 6.0})
+	assert.NoError(t, err)
+	assert.Len(t, parents, 2)
+	assert.Equal(t, 0.0, parents[0].RemainingGrams)
+	assert.Equal(t, 0.0, parents[1].RemainingGrams)
+
+	stub.AssertExpectations(t)
+}
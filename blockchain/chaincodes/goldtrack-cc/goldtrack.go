@@ -17,16 +17,23 @@ import (
 
 // GoldTransaction represente une transaction d'or sur la chaine.
 type GoldTransaction struct {
-	ID            string            `json:"id"`
-	SiteID        string            `json:"siteId"`
-	FromEntity    string            `json:"fromEntity"`
-	ToEntity      string            `json:"toEntity"`
-	QuantityGrams float64           `json:"quantityGrams"`
-	IsLegal       bool              `json:"isLegal"`
-	H3Index       string            `json:"h3Index"`
-	Metadata      map[string]string `json:"metadata,omitempty"`
-	CreatedAt     string            `json:"createdAt"`
-	CreatedBy     string            `json:"createdBy"`
+	ID             string            `json:"id"`
+	SiteID         string            `json:"siteId"`
+	FromEntity     string            `json:"fromEntity"`
+	ToEntity       string            `json:"toEntity"`
+	QuantityGrams  float64           `json:"quantityGrams"`
+	IsLegal        bool              `json:"isLegal"`
+	H3Index        string            `json:"h3Index"`
+	H3Resolution   int               `json:"h3Resolution"`
+	H3Parents      []string          `json:"h3Parents,omitempty"`
+	LotID          string            `json:"lotId"`
+	ParentTxIDs    []string          `json:"parentTxIds,omitempty"`
+	RemainingGrams float64           `json:"remainingGrams"`
+	Flagged        bool              `json:"flagged,omitempty"`
+	FlagReason     string            `json:"flagReason,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	CreatedAt      string            `json:"createdAt"`
+	CreatedBy      string            `json:"createdBy"`
 }
 
 // DivergenceResult represente le score de divergence pour une zone H3.
@@ -55,6 +62,11 @@ func (gc *GoldTrackContract) RecordTransaction(
 	quantityGrams float64,
 	isLegal bool,
 	h3Index string,
+	h3Resolution int,
+	h3Parents []string,
+	lotID string,
+	parentTxIDs []string,
+	parentAllocations []float64,
 	createdBy string,
 ) error {
 	// Verifier que la transaction n'existe pas deja
@@ -70,18 +82,28 @@ func (gc *GoldTrackContract) RecordTransaction(
 		return fmt.Errorf("la quantite doit etre positive: %f", quantityGrams)
 	}
 
+	parents, err := gc.validateAndDebitParents(ctx, fromEntity, quantityGrams, parentTxIDs, parentAllocations)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now().UTC().Format(time.RFC3339)
 
 	tx := GoldTransaction{
-		ID:            id,
-		SiteID:        siteID,
-		FromEntity:    fromEntity,
-		ToEntity:      toEntity,
-		QuantityGrams: quantityGrams,
-		IsLegal:       isLegal,
-		H3Index:       h3Index,
-		CreatedAt:     now,
-		CreatedBy:     createdBy,
+		ID:             id,
+		SiteID:         siteID,
+		FromEntity:     fromEntity,
+		ToEntity:       toEntity,
+		QuantityGrams:  quantityGrams,
+		IsLegal:        isLegal,
+		H3Index:        h3Index,
+		H3Resolution:   h3Resolution,
+		H3Parents:      h3Parents,
+		LotID:          lotID,
+		ParentTxIDs:    parentTxIDs,
+		RemainingGrams: quantityGrams,
+		CreatedAt:      now,
+		CreatedBy:      createdBy,
 	}
 
 	txJSON, err := json.Marshal(tx)
@@ -94,6 +116,22 @@ func (gc *GoldTrackContract) RecordTransaction(
 		return fmt.Errorf("echec ecriture state: %w", err)
 	}
 
+	// Persister le debit de solde des transactions parentes
+	for _, parent := range parents {
+		parentJSON, err := json.Marshal(parent)
+		if err != nil {
+			return fmt.Errorf("echec serialisation transaction parente %s: %w", parent.ID, err)
+		}
+		if err := ctx.GetStub().PutState(parent.ID, parentJSON); err != nil {
+			return fmt.Errorf("echec ecriture transaction parente %s: %w", parent.ID, err)
+		}
+	}
+
+	// Index de chaine de possession : lot et lien parent~enfant
+	if err := indexCustody(ctx, id, lotID, parentTxIDs); err != nil {
+		return err
+	}
+
 	// Index composite par entite (from)
 	fromKey, err := ctx.GetStub().CreateCompositeKey("from~id", []string{fromEntity, id})
 	if err != nil {
@@ -121,6 +159,11 @@ func (gc *GoldTrackContract) RecordTransaction(
 		return fmt.Errorf("echec ecriture cle composite h3: %w", err)
 	}
 
+	// Index hierarchique H3 (resolution exacte + chaine de parents)
+	if err := indexH3Hierarchy(ctx, id, h3Index, h3Resolution, h3Parents); err != nil {
+		return err
+	}
+
 	// Index composite par site
 	siteKey, err := ctx.GetStub().CreateCompositeKey("site~id", []string{siteID, id})
 	if err != nil {
@@ -130,6 +173,32 @@ func (gc *GoldTrackContract) RecordTransaction(
 		return fmt.Errorf("echec ecriture cle composite site: %w", err)
 	}
 
+	// Index composites entite/site/H3 + horodatage, pour que les requetes
+	// paginees par plage de temps (QueryByEntityPaginated, QueryBySitePaginated,
+	// QueryByH3Paginated) puissent seeker directement sur la borne de temps
+	// plutot que de balayer chaque page et de la filtrer en memoire.
+	for _, timeIndex := range []struct {
+		objectType string
+		keyAttr    string
+	}{
+		{"from~time~id", fromEntity},
+		{"to~time~id", toEntity},
+		{"site~time~id", siteID},
+		{"h3~time~id", h3Index},
+	} {
+		timeKey, err := ctx.GetStub().CreateCompositeKey(timeIndex.objectType, []string{timeIndex.keyAttr, now, id})
+		if err != nil {
+			return fmt.Errorf("echec creation cle composite %s: %w", timeIndex.objectType, err)
+		}
+		if err := ctx.GetStub().PutState(timeKey, []byte{0x00}); err != nil {
+			return fmt.Errorf("echec ecriture cle composite %s: %w", timeIndex.objectType, err)
+		}
+	}
+
+	if err := ctx.GetStub().SetEvent("GoldTransactionRecorded", txJSON); err != nil {
+		return fmt.Errorf("echec emission evenement: %w", err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,214 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  GoldTrack Smart Contract Unit Tests
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// ---------------------------------------------------------------------------
+// Mock ChaincodeStub
+// ---------------------------------------------------------------------------
+type MockChaincodeStub struct {
+	mock.Mock
+	shim.ChaincodeStubInterface
+	state map[string][]byte
+}
+
+func NewMockChaincodeStub() *MockChaincodeStub {
+	return &MockChaincodeStub{state: make(map[string][]byte)}
+}
+
+func (m *MockChaincodeStub) GetState(key string) ([]byte, error) {
+	args := m.Called(key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockChaincodeStub) PutState(key string, value []byte) error {
+	args := m.Called(key, value)
+	m.state[key] = value
+	return args.Error(0)
+}
+
+func (m *MockChaincodeStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	args := m.Called(objectType, attributes)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockChaincodeStub) GetStateByPartialCompositeKey(objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	args := m.Called(objectType, keys)
+	return args.Get(0).(shim.StateQueryIteratorInterface), args.Error(1)
+}
+
+func (m *MockChaincodeStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	args := m.Called(compositeKey)
+	return args.String(0), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockChaincodeStub) GetTxID() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockChaincodeStub) SetEvent(name string, payload []byte) error {
+	args := m.Called(name, payload)
+	return args.Error(0)
+}
+
+func (m *MockChaincodeStub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	args := m.Called(startKey, endKey, pageSize, bookmark)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(*peer.QueryResponseMetadata), args.Error(2)
+	}
+	return args.Get(0).(shim.StateQueryIteratorInterface), args.Get(1).(*peer.QueryResponseMetadata), args.Error(2)
+}
+
+// mockStateQueryIterator is a minimal in-memory implementation of
+// shim.StateQueryIteratorInterface used to exercise composite-key and range
+// queries without a real ledger.
+type mockStateQueryIterator struct {
+	shim.StateQueryIteratorInterface
+	results []*queryresultKV
+	pos     int
+}
+
+type queryresultKV struct {
+	Key   string
+	Value []byte
+}
+
+func (m *mockStateQueryIterator) HasNext() bool {
+	return m.pos < len(m.results)
+}
+
+func (m *mockStateQueryIterator) Next() (*queryresult.KV, error) {
+	kv := m.results[m.pos]
+	m.pos++
+	return &queryresult.KV{Key: kv.Key, Value: kv.Value}, nil
+}
+
+func (m *mockStateQueryIterator) Close() error {
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Mock TransactionContext
+// ---------------------------------------------------------------------------
+type MockTransactionContext struct {
+	contractapi.TransactionContext
+	stub *MockChaincodeStub
+}
+
+func (m *MockTransactionContext) GetStub() shim.ChaincodeStubInterface {
+	return m.stub
+}
+
+// ---------------------------------------------------------------------------
+// Tests
+// ---------------------------------------------------------------------------
+
+// expectTimeIndexWrites mocks the four from~time~id/to~time~id/site~time~id/
+// h3~time~id composite key writes RecordTransaction makes for every
+// transaction, regardless of parents. Attributes include the wall-clock
+// timestamp, so mock.AnythingOfType is used for the attribute slice rather
+// than asserting an exact value.
+func expectTimeIndexWrites(stub *MockChaincodeStub) {
+	for _, objectType := range []string{"from~time~id", "to~time~id", "site~time~id", "h3~time~id"} {
+		stub.On("CreateCompositeKey", objectType, mock.AnythingOfType("[]string")).Return(objectType+"-key", nil).Once()
+		stub.On("PutState", objectType+"-key", []byte{0x00}).Return(nil).Once()
+	}
+}
+
+func TestRecordTransaction_NoParents(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	gc := new(GoldTrackContract)
+
+	id := "TX-001"
+
+	stub.On("GetState", id).Return(nil, nil).Once()
+	stub.On("PutState", id, mock.AnythingOfType("[]uint8")).Return(nil).Once()
+	stub.On("CreateCompositeKey", "lot~id", []string{"LOT-001", id}).Return("lot-key", nil).Once()
+	stub.On("PutState", "lot-key", []byte{0x00}).Return(nil).Once()
+	stub.On("CreateCompositeKey", "from~id", []string{"COOP-A", id}).Return("from-key", nil).Once()
+	stub.On("PutState", "from-key", []byte{0x00}).Return(nil).Once()
+	stub.On("CreateCompositeKey", "to~id", []string{"COOP-B", id}).Return("to-key", nil).Once()
+	stub.On("PutState", "to-key", []byte{0x00}).Return(nil).Once()
+	stub.On("CreateCompositeKey", "h3~id", []string{"862a1072fffffff", id}).Return("h3-key", nil).Once()
+	stub.On("PutState", "h3-key", []byte{0x00}).Return(nil).Once()
+	stub.On("CreateCompositeKey", "h3res~res~cell~id", []string{"9", "862a1072fffffff", id}).Return("h3res-key", nil).Once()
+	stub.On("PutState", "h3res-key", []byte{0x00}).Return(nil).Once()
+	stub.On("CreateCompositeKey", "site~id", []string{"SITE-001", id}).Return("site-key", nil).Once()
+	stub.On("PutState", "site-key", []byte{0x00}).Return(nil).Once()
+	expectTimeIndexWrites(stub)
+	stub.On("SetEvent", "GoldTransactionRecorded", mock.AnythingOfType("[]uint8")).Return(nil).Once()
+
+	err := gc.RecordTransaction(ctx, id, "SITE-001", "COOP-A", "COOP-B", 10.0, true, "862a1072fffffff", 9, nil, "LOT-001", nil, nil, "COOP-A")
+	assert.NoError(t, err)
+
+	var tx GoldTransaction
+	assert.NoError(t, json.Unmarshal(stub.state[id], &tx))
+	assert.Equal(t, 10.0, tx.RemainingGrams)
+
+	stub.AssertExpectations(t)
+}
+
+func TestRecordTransaction_AlreadyExists(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	gc := new(GoldTrackContract)
+
+	stub.On("GetState", "TX-001").Return([]byte(`{"id":"TX-001"}`), nil).Once()
+
+	err := gc.RecordTransaction(ctx, "TX-001", "SITE-001", "COOP-A", "COOP-B", 10.0, true, "862a1072fffffff", 9, nil, "LOT-001", nil, nil, "COOP-A")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "existe deja")
+
+	stub.AssertExpectations(t)
+}
+
+func TestRecordTransaction_DuplicateParent(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	gc := new(GoldTrackContract)
+
+	id := "TX-002"
+	stub.On("GetState", id).Return(nil, nil).Once()
+
+	err := gc.RecordTransaction(ctx, id, "SITE-001", "COOP-A", "COOP-B", 16.0, true, "862a1072fffffff", 9, nil, "LOT-001", []string{"P1", "P1"}, []float64{8.0, 8.0}, "COOP-A")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "plusieurs fois")
+
+	stub.AssertExpectations(t)
+}
+
+func TestRecordTransaction_MassBalanceMismatch(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	gc := new(GoldTrackContract)
+
+	id := "TX-003"
+	parent := GoldTransaction{ID: "P1", ToEntity: "COOP-A", RemainingGrams: 10.0}
+	parentJSON, _ := json.Marshal(parent)
+
+	stub.On("GetState", id).Return(nil, nil).Once()
+	stub.On("GetState", "P1").Return(parentJSON, nil).Once()
+
+	err := gc.RecordTransaction(ctx, id, "SITE-001", "COOP-A", "COOP-B", 5.0, true, "862a1072fffffff", 9, nil, "LOT-001", []string{"P1"}, []float64{8.0}, "COOP-A")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ne correspond pas a la quantite")
+
+	stub.AssertExpectations(t)
+}
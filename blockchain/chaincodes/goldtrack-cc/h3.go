@@ -0,0 +1,234 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Roll-ups hierarchiques H3 et requetes de voisinage
+//
+// Le chaincode ne peut pas depender de bindings H3 en cgo a l'execution, donc
+// la chaine de parents d'un index H3 (une cellule par resolution plus
+// grossiere) est calculee hors-chaine et fournie a RecordTransaction. Elle
+// est indexee ici afin qu'un score de divergence puisse etre agrege a
+// n'importe quel niveau de zoom sans refaire l'arithmetique H3 sur la chaine.
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// HierarchicalDivergenceResult est le score de divergence agrege pour une
+// cellule H3 a une resolution donnee, avec le detail par cellule enfant
+// effectivement enregistree sous cette cellule.
+type HierarchicalDivergenceResult struct {
+	H3Index          string              `json:"h3Index"`
+	Resolution       int                 `json:"resolution"`
+	TotalLegal       float64             `json:"totalLegal"`
+	TotalIllegal     float64             `json:"totalIllegal"`
+	DivergenceScore  float64             `json:"divergenceScore"`
+	TransactionCount int                 `json:"transactionCount"`
+	Children         []*DivergenceResult `json:"children"`
+}
+
+// indexH3Hierarchy ecrit, en plus de l'index h3~id sur la cellule exacte,
+// un index h3res~res~cell~id pour la cellule exacte et chacun de ses
+// parents, ainsi qu'un index h3parent~parent~id reliant directement chaque
+// parent a la transaction. h3Parents est ordonne du parent immediat
+// (resolution-1) vers les resolutions les plus grossieres.
+func indexH3Hierarchy(
+	ctx contractapi.TransactionContextInterface,
+	id string,
+	h3Index string,
+	h3Resolution int,
+	h3Parents []string,
+) error {
+	if err := putH3ResolutionIndex(ctx, id, h3Index, h3Resolution); err != nil {
+		return err
+	}
+
+	for i, parentCell := range h3Parents {
+		parentResolution := h3Resolution - (i + 1)
+
+		if err := putH3ResolutionIndex(ctx, id, parentCell, parentResolution); err != nil {
+			return err
+		}
+
+		parentKey, err := ctx.GetStub().CreateCompositeKey("h3parent~parent~id", []string{parentCell, id})
+		if err != nil {
+			return fmt.Errorf("echec creation cle composite h3parent: %w", err)
+		}
+		if err := ctx.GetStub().PutState(parentKey, []byte{0x00}); err != nil {
+			return fmt.Errorf("echec ecriture cle composite h3parent: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func putH3ResolutionIndex(ctx contractapi.TransactionContextInterface, id, cell string, resolution int) error {
+	key, err := ctx.GetStub().CreateCompositeKey("h3res~res~cell~id", []string{strconv.Itoa(resolution), cell, id})
+	if err != nil {
+		return fmt.Errorf("echec creation cle composite h3res: %w", err)
+	}
+	if err := ctx.GetStub().PutState(key, []byte{0x00}); err != nil {
+		return fmt.Errorf("echec ecriture cle composite h3res: %w", err)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// ComputeDivergenceScoreAtResolution agrege toutes les transactions dont la
+// chaine de parents H3 passe par cellIndex a la resolution donnee, qu'elles
+// y aient ete enregistrees directement ou a une resolution plus fine. Le
+// detail par cellule enfant reellement enregistree est retourne pour
+// permettre a un visualiseur hors-chaine de construire une choroplethe sans
+// second aller-retour.
+// ---------------------------------------------------------------------------
+func (gc *GoldTrackContract) ComputeDivergenceScoreAtResolution(
+	ctx contractapi.TransactionContextInterface,
+	cellIndex string,
+	resolution int,
+) (*HierarchicalDivergenceResult, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("h3res~res~cell~id", []string{strconv.Itoa(resolution), cellIndex})
+	if err != nil {
+		return nil, fmt.Errorf("echec requete H3 hierarchique %s/%d: %w", cellIndex, resolution, err)
+	}
+	defer iterator.Close()
+
+	children := make(map[string]*DivergenceResult)
+	var totalLegal, totalIllegal float64
+	var txCount int
+
+	for iterator.HasNext() {
+		responseRange, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("echec iteration H3 hierarchique: %w", err)
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(responseRange.Key)
+		if err != nil || len(parts) < 3 {
+			continue
+		}
+
+		txID := parts[2]
+		tx, err := gc.GetTransaction(ctx, txID)
+		if err != nil {
+			continue
+		}
+
+		txCount++
+
+		child, ok := children[tx.H3Index]
+		if !ok {
+			child = &DivergenceResult{H3Index: tx.H3Index}
+			children[tx.H3Index] = child
+		}
+
+		if tx.IsLegal {
+			totalLegal += tx.QuantityGrams
+			child.TotalLegal += tx.QuantityGrams
+		} else {
+			totalIllegal += tx.QuantityGrams
+			child.TotalIllegal += tx.QuantityGrams
+		}
+		child.TransactionCount++
+	}
+
+	childList := make([]*DivergenceResult, 0, len(children))
+	for _, child := range children {
+		childTotal := child.TotalLegal + child.TotalIllegal
+		if childTotal > 0 {
+			child.DivergenceScore = math.Abs(child.TotalLegal-child.TotalIllegal) / childTotal
+		}
+		childList = append(childList, child)
+	}
+
+	total := totalLegal + totalIllegal
+	var divergenceScore float64
+	if total > 0 {
+		divergenceScore = math.Abs(totalLegal-totalIllegal) / total
+	}
+
+	return &HierarchicalDivergenceResult{
+		H3Index:          cellIndex,
+		Resolution:       resolution,
+		TotalLegal:       totalLegal,
+		TotalIllegal:     totalIllegal,
+		DivergenceScore:  divergenceScore,
+		TransactionCount: txCount,
+		Children:         childList,
+	}, nil
+}
+
+// ---------------------------------------------------------------------------
+// QueryByH3Ring est bloquee en attendant confirmation de l'equipe qui
+// construit l'appelant hors-chaine : la demande initiale decrivait
+// QueryByH3Ring(centerH3, k) avec le k-ring calcule cote chaincode, ce que
+// l'arithmetique H3 complete (indisponible sans bindings cgo ici) ne permet
+// pas d'honorer telle quelle. Plutot que de substituer silencieusement une
+// autre signature sous ce nom, l'API demandee echoue explicitement ; voir
+// QueryByH3Cells pour la requete equivalente sur des cellules precalculees
+// hors-chaine, en attendant que cette confirmation soit obtenue.
+// ---------------------------------------------------------------------------
+func (gc *GoldTrackContract) QueryByH3Ring(
+	ctx contractapi.TransactionContextInterface,
+	centerH3 string,
+	k int,
+) ([]*GoldTransaction, error) {
+	return nil, fmt.Errorf("QueryByH3Ring(centerH3, k) n'est pas implementee : le calcul du k-ring necessite une confirmation de l'equipe appelante hors-chaine avant implementation (voir QueryByH3Cells pour la variante a cellules precalculees)")
+}
+
+// ---------------------------------------------------------------------------
+// QueryByH3Cells retourne toutes les transactions enregistrees sur
+// l'ensemble de cellules H3 fourni. Le calcul geometrique du k-ring
+// (voisinage a distance k d'une cellule centrale) necessite l'arithmetique
+// H3 complete, indisponible sans bindings cgo dans le chaincode : il est
+// donc effectue hors-chaine par l'appelant (pipeline IA ou tableau de bord),
+// qui fournit ici la liste des cellules deja resolues.
+// ---------------------------------------------------------------------------
+func (gc *GoldTrackContract) QueryByH3Cells(
+	ctx contractapi.TransactionContextInterface,
+	ringCells []string,
+) ([]*GoldTransaction, error) {
+	if len(ringCells) == 0 {
+		return nil, fmt.Errorf("la liste de cellules ne peut pas etre vide")
+	}
+
+	txMap := make(map[string]bool)
+	var transactions []*GoldTransaction
+
+	for _, cell := range ringCells {
+		iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("h3~id", []string{cell})
+		if err != nil {
+			return nil, fmt.Errorf("echec requete H3 pour la cellule %s: %w", cell, err)
+		}
+
+		for iterator.HasNext() {
+			responseRange, err := iterator.Next()
+			if err != nil {
+				iterator.Close()
+				return nil, fmt.Errorf("echec iteration H3 ring: %w", err)
+			}
+
+			_, parts, err := ctx.GetStub().SplitCompositeKey(responseRange.Key)
+			if err != nil || len(parts) < 2 {
+				continue
+			}
+
+			txID := parts[1]
+			if txMap[txID] {
+				continue
+			}
+			txMap[txID] = true
+
+			tx, err := gc.GetTransaction(ctx, txID)
+			if err == nil {
+				transactions = append(transactions, tx)
+			}
+		}
+
+		iterator.Close()
+	}
+
+	return transactions, nil
+}
@@ -0,0 +1,97 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  H3 Rollups and Ring Queries Unit Tests
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeDivergenceScoreAtResolution(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	gc := new(GoldTrackContract)
+
+	legalTx := GoldTransaction{ID: "TX-1", H3Index: "862a1072fffffff", IsLegal: true, QuantityGrams: 10.0}
+	illegalTx := GoldTransaction{ID: "TX-2", H3Index: "862a1072fffffff", IsLegal: false, QuantityGrams: 5.0}
+	legalJSON, _ := json.Marshal(legalTx)
+	illegalJSON, _ := json.Marshal(illegalTx)
+
+	iterator := &mockStateQueryIterator{results: []*queryresultKV{
+		{Key: "h3res~res~cell~id\x007\x00862a0000fffffff\x00TX-1\x00"},
+		{Key: "h3res~res~cell~id\x007\x00862a0000fffffff\x00TX-2\x00"},
+	}}
+	stub.On("GetStateByPartialCompositeKey", "h3res~res~cell~id", []string{"7", "862a0000fffffff"}).Return(iterator, nil).Once()
+	stub.On("SplitCompositeKey", "h3res~res~cell~id\x007\x00862a0000fffffff\x00TX-1\x00").
+		Return("h3res~res~cell~id", []string{"7", "862a0000fffffff", "TX-1"}, nil).Once()
+	stub.On("SplitCompositeKey", "h3res~res~cell~id\x007\x00862a0000fffffff\x00TX-2\x00").
+		Return("h3res~res~cell~id", []string{"7", "862a0000fffffff", "TX-2"}, nil).Once()
+	stub.On("GetState", "TX-1").Return(legalJSON, nil).Once()
+	stub.On("GetState", "TX-2").Return(illegalJSON, nil).Once()
+
+	result, err := gc.ComputeDivergenceScoreAtResolution(ctx, "862a0000fffffff", 7)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.TransactionCount)
+	assert.Equal(t, 10.0, result.TotalLegal)
+	assert.Equal(t, 5.0, result.TotalIllegal)
+	assert.InDelta(t, 1.0/3.0, result.DivergenceScore, 1e-9)
+	assert.Len(t, result.Children, 1)
+
+	stub.AssertExpectations(t)
+}
+
+func TestQueryByH3Cells_DedupesAcrossCells(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	gc := new(GoldTrackContract)
+
+	tx := GoldTransaction{ID: "TX-1", H3Index: "862a1072fffffff"}
+	txJSON, _ := json.Marshal(tx)
+
+	cellAIterator := &mockStateQueryIterator{results: []*queryresultKV{
+		{Key: "h3~id\x00862a1072fffffff\x00TX-1\x00"},
+	}}
+	cellBIterator := &mockStateQueryIterator{results: []*queryresultKV{
+		{Key: "h3~id\x00862a1073fffffff\x00TX-1\x00"},
+	}}
+	stub.On("GetStateByPartialCompositeKey", "h3~id", []string{"862a1072fffffff"}).Return(cellAIterator, nil).Once()
+	stub.On("GetStateByPartialCompositeKey", "h3~id", []string{"862a1073fffffff"}).Return(cellBIterator, nil).Once()
+	stub.On("SplitCompositeKey", "h3~id\x00862a1072fffffff\x00TX-1\x00").
+		Return("h3~id", []string{"862a1072fffffff", "TX-1"}, nil).Once()
+	stub.On("SplitCompositeKey", "h3~id\x00862a1073fffffff\x00TX-1\x00").
+		Return("h3~id", []string{"862a1073fffffff", "TX-1"}, nil).Once()
+	stub.On("GetState", "TX-1").Return(txJSON, nil).Once()
+
+	transactions, err := gc.QueryByH3Cells(ctx, []string{"862a1072fffffff", "862a1073fffffff"})
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 1)
+
+	stub.AssertExpectations(t)
+}
+
+func TestQueryByH3Cells_EmptyCellList(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	gc := new(GoldTrackContract)
+
+	_, err := gc.QueryByH3Cells(ctx, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "vide")
+
+	stub.AssertExpectations(t)
+}
+
+func TestQueryByH3Ring_BlockedPendingSignOff(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	gc := new(GoldTrackContract)
+
+	_, err := gc.QueryByH3Ring(ctx, "862a1072fffffff", 2)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "n'est pas implementee")
+
+	stub.AssertExpectations(t)
+}
@@ -0,0 +1,260 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Requetes paginees avec filtrage par plage de temps
+//
+// QueryByEntity, QueryBySite et la lecture d'une cellule H3 materialisent
+// l'integralite du resultat en memoire, ce qui finira par depasser la
+// limite de taille des transactions Fabric pour une cooperative active ou
+// une cellule H3 tres frequentee. Les variantes paginees ci-dessous
+// utilisent les index composites entite/site/H3 + horodatage ecrits par
+// RecordTransaction (from~time~id, to~time~id, site~time~id, h3~time~id) et
+// seekent directement sur la plage de temps demandee via
+// GetStateByRangeWithPagination, plutot que de balayer chaque page puis de
+// la filtrer en memoire : une borne de temps etroite ne paie que le cout du
+// seek, pas celui du balayage complet de l'entite/du site/de la cellule.
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// PagedResult est le resultat paginé d'une requete de transactions.
+type PagedResult struct {
+	Transactions []*GoldTransaction `json:"transactions"`
+	NextBookmark string             `json:"nextBookmark"`
+	FetchedCount int32              `json:"fetchedCount"`
+}
+
+// entityPageCursor encode, dans le bookmark expose au client, le cote de
+// l'index entite~temps~id actuellement parcouru. L'index from~time~id et
+// l'index to~time~id sont deux balayages Fabric distincts ; un seul bookmark
+// opaque ne peut pas les enjamber, donc QueryByEntityPaginated parcourt
+// d'abord from~time~id puis bascule sur to~time~id une fois celui-ci epuise.
+type entityPageCursor struct {
+	Side     string `json:"side"`
+	Bookmark string `json:"bookmark"`
+}
+
+func decodeEntityCursor(bookmark string) entityPageCursor {
+	if bookmark == "" {
+		return entityPageCursor{Side: "from"}
+	}
+	var cursor entityPageCursor
+	if err := json.Unmarshal([]byte(bookmark), &cursor); err != nil {
+		return entityPageCursor{Side: "from"}
+	}
+	return cursor
+}
+
+func encodeEntityCursor(cursor entityPageCursor) string {
+	cursorJSON, err := json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+	return string(cursorJSON)
+}
+
+// timeRangeBounds construit les bornes [startKey, endKey) d'un seek sur un
+// index composite objectType~time~id pour la valeur de cle keyAttr (entite,
+// site ou cellule H3), en repliant la plage de temps RFC3339 optionnelle
+// directement dans les bornes plutot que de filtrer apres coup. Une borne de
+// temps vide retombe sur le prefixe complet de keyAttr, equivalent a
+// GetStateByPartialCompositeKey. La borne superieure, quand toTime est
+// fourni, ajoute un caractere superieur a tout id reel (utf8.MaxRune) pour
+// que les transactions dont l'horodatage vaut exactement toTime restent
+// incluses.
+func timeRangeBounds(ctx contractapi.TransactionContextInterface, objectType, keyAttr, fromTime, toTime string) (string, string, error) {
+	startAttrs := []string{keyAttr}
+	if fromTime != "" {
+		startAttrs = append(startAttrs, fromTime)
+	}
+	startKey, err := ctx.GetStub().CreateCompositeKey(objectType, startAttrs)
+	if err != nil {
+		return "", "", fmt.Errorf("echec creation borne inferieure %s: %w", objectType, err)
+	}
+
+	var endAttrs []string
+	if toTime != "" {
+		endAttrs = []string{keyAttr, toTime, string(utf8.MaxRune)}
+	} else {
+		endAttrs = []string{keyAttr + string(utf8.MaxRune)}
+	}
+	endKey, err := ctx.GetStub().CreateCompositeKey(objectType, endAttrs)
+	if err != nil {
+		return "", "", fmt.Errorf("echec creation borne superieure %s: %w", objectType, err)
+	}
+
+	return startKey, endKey, nil
+}
+
+// ---------------------------------------------------------------------------
+// QueryByEntityPaginated retourne une page de transactions impliquant une
+// entite (emetteur ou destinataire), filtrees sur la plage de temps donnee.
+// ---------------------------------------------------------------------------
+func (gc *GoldTrackContract) QueryByEntityPaginated(
+	ctx contractapi.TransactionContextInterface,
+	entity string,
+	pageSize int32,
+	bookmark string,
+	fromTime string,
+	toTime string,
+) (*PagedResult, error) {
+	cursor := decodeEntityCursor(bookmark)
+
+	objectType := "from~time~id"
+	if cursor.Side == "to" {
+		objectType = "to~time~id"
+	}
+
+	startKey, endKey, err := timeRangeBounds(ctx, objectType, entity, fromTime, toTime)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, metadata, err := ctx.GetStub().GetStateByRangeWithPagination(startKey, endKey, pageSize, cursor.Bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("echec requete paginee %s: %w", objectType, err)
+	}
+	defer iterator.Close()
+
+	var transactions []*GoldTransaction
+
+	for iterator.HasNext() {
+		responseRange, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("echec iteration paginee %s: %w", objectType, err)
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(responseRange.Key)
+		if err != nil || len(parts) < 3 {
+			continue
+		}
+
+		tx, err := gc.GetTransaction(ctx, parts[2])
+		if err == nil {
+			transactions = append(transactions, tx)
+		}
+	}
+
+	nextCursor := entityPageCursor{Side: cursor.Side, Bookmark: metadata.GetBookmark()}
+	if metadata.GetFetchedRecordsCount() < pageSize {
+		if cursor.Side == "from" {
+			nextCursor = entityPageCursor{Side: "to", Bookmark: ""}
+		} else {
+			nextCursor = entityPageCursor{Side: "done"}
+		}
+	}
+
+	nextBookmark := encodeEntityCursor(nextCursor)
+	if nextCursor.Side == "done" {
+		nextBookmark = ""
+	}
+
+	return &PagedResult{
+		Transactions: transactions,
+		NextBookmark: nextBookmark,
+		FetchedCount: metadata.GetFetchedRecordsCount(),
+	}, nil
+}
+
+// ---------------------------------------------------------------------------
+// QueryBySitePaginated retourne une page de transactions d'un site minier,
+// filtrees sur la plage de temps donnee.
+// ---------------------------------------------------------------------------
+func (gc *GoldTrackContract) QueryBySitePaginated(
+	ctx contractapi.TransactionContextInterface,
+	siteID string,
+	pageSize int32,
+	bookmark string,
+	fromTime string,
+	toTime string,
+) (*PagedResult, error) {
+	startKey, endKey, err := timeRangeBounds(ctx, "site~time~id", siteID, fromTime, toTime)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, metadata, err := ctx.GetStub().GetStateByRangeWithPagination(startKey, endKey, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("echec requete paginee site: %w", err)
+	}
+	defer iterator.Close()
+
+	var transactions []*GoldTransaction
+
+	for iterator.HasNext() {
+		responseRange, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("echec iteration paginee site: %w", err)
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(responseRange.Key)
+		if err != nil || len(parts) < 3 {
+			continue
+		}
+
+		tx, err := gc.GetTransaction(ctx, parts[2])
+		if err == nil {
+			transactions = append(transactions, tx)
+		}
+	}
+
+	return &PagedResult{
+		Transactions: transactions,
+		NextBookmark: metadata.GetBookmark(),
+		FetchedCount: metadata.GetFetchedRecordsCount(),
+	}, nil
+}
+
+// ---------------------------------------------------------------------------
+// QueryByH3Paginated retourne une page de transactions d'une cellule H3
+// exacte, filtrees sur la plage de temps donnee.
+// ---------------------------------------------------------------------------
+func (gc *GoldTrackContract) QueryByH3Paginated(
+	ctx contractapi.TransactionContextInterface,
+	h3Index string,
+	pageSize int32,
+	bookmark string,
+	fromTime string,
+	toTime string,
+) (*PagedResult, error) {
+	startKey, endKey, err := timeRangeBounds(ctx, "h3~time~id", h3Index, fromTime, toTime)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, metadata, err := ctx.GetStub().GetStateByRangeWithPagination(startKey, endKey, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("echec requete paginee H3: %w", err)
+	}
+	defer iterator.Close()
+
+	var transactions []*GoldTransaction
+
+	for iterator.HasNext() {
+		responseRange, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("echec iteration paginee H3: %w", err)
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(responseRange.Key)
+		if err != nil || len(parts) < 3 {
+			continue
+		}
+
+		tx, err := gc.GetTransaction(ctx, parts[2])
+		if err == nil {
+			transactions = append(transactions, tx)
+		}
+	}
+
+	return &PagedResult{
+		Transactions: transactions,
+		NextBookmark: metadata.GetBookmark(),
+		FetchedCount: metadata.GetFetchedRecordsCount(),
+	}, nil
+}
@@ -0,0 +1,102 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Paginated Time-Range Queries Unit Tests
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryByEntityPaginated_SeeksOnTimeRange(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	gc := new(GoldTrackContract)
+
+	tx := GoldTransaction{ID: "TX-1", FromEntity: "COOP-A", CreatedAt: "2024-02-01T00:00:00Z"}
+	txJSON, _ := json.Marshal(tx)
+
+	startKey := "from~time~id\x00COOP-A\x002024-01-01T00:00:00Z\x00"
+	endKey := "from~time~id\x00COOP-A\x002024-03-01T00:00:00Z\x00" + string(utf8.MaxRune) + "\x00"
+	resultKey := "from~time~id\x00COOP-A\x002024-02-01T00:00:00Z\x00TX-1\x00"
+
+	stub.On("CreateCompositeKey", "from~time~id", []string{"COOP-A", "2024-01-01T00:00:00Z"}).Return(startKey, nil).Once()
+	stub.On("CreateCompositeKey", "from~time~id", []string{"COOP-A", "2024-03-01T00:00:00Z", string(utf8.MaxRune)}).Return(endKey, nil).Once()
+
+	iterator := &mockStateQueryIterator{results: []*queryresultKV{{Key: resultKey}}}
+	metadata := &peer.QueryResponseMetadata{FetchedRecordsCount: 1, Bookmark: ""}
+	stub.On("GetStateByRangeWithPagination", startKey, endKey, int32(10), "").Return(iterator, metadata, nil).Once()
+	stub.On("SplitCompositeKey", resultKey).Return("from~time~id", []string{"COOP-A", "2024-02-01T00:00:00Z", "TX-1"}, nil).Once()
+	stub.On("GetState", "TX-1").Return(txJSON, nil).Once()
+
+	result, err := gc.QueryByEntityPaginated(ctx, "COOP-A", 10, "", "2024-01-01T00:00:00Z", "2024-03-01T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Len(t, result.Transactions, 1)
+	assert.Equal(t, "TX-1", result.Transactions[0].ID)
+	// Fewer results than pageSize on the "from" side flips the cursor to "to".
+	assert.NotEmpty(t, result.NextBookmark)
+
+	stub.AssertExpectations(t)
+}
+
+func TestQueryBySitePaginated_SeeksOnTimeRange(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	gc := new(GoldTrackContract)
+
+	tx := GoldTransaction{ID: "TX-1", SiteID: "SITE-001", CreatedAt: "2024-02-01T00:00:00Z"}
+	txJSON, _ := json.Marshal(tx)
+
+	startKey := "site~time~id\x00SITE-001\x00"
+	endKey := "site~time~id\x00SITE-001" + string(utf8.MaxRune) + "\x00"
+	resultKey := "site~time~id\x00SITE-001\x002024-02-01T00:00:00Z\x00TX-1\x00"
+
+	stub.On("CreateCompositeKey", "site~time~id", []string{"SITE-001"}).Return(startKey, nil).Once()
+	stub.On("CreateCompositeKey", "site~time~id", []string{"SITE-001" + string(utf8.MaxRune)}).Return(endKey, nil).Once()
+
+	iterator := &mockStateQueryIterator{results: []*queryresultKV{{Key: resultKey}}}
+	metadata := &peer.QueryResponseMetadata{FetchedRecordsCount: 1, Bookmark: "next"}
+	stub.On("GetStateByRangeWithPagination", startKey, endKey, int32(10), "").Return(iterator, metadata, nil).Once()
+	stub.On("SplitCompositeKey", resultKey).Return("site~time~id", []string{"SITE-001", "2024-02-01T00:00:00Z", "TX-1"}, nil).Once()
+	stub.On("GetState", "TX-1").Return(txJSON, nil).Once()
+
+	result, err := gc.QueryBySitePaginated(ctx, "SITE-001", 10, "", "", "")
+	assert.NoError(t, err)
+	assert.Len(t, result.Transactions, 1)
+	assert.Equal(t, "next", result.NextBookmark)
+	assert.Equal(t, int32(1), result.FetchedCount)
+
+	stub.AssertExpectations(t)
+}
+
+func TestQueryByH3Paginated_SeeksOnTimeRange(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	gc := new(GoldTrackContract)
+
+	tx := GoldTransaction{ID: "TX-1", H3Index: "862a1072fffffff", CreatedAt: "2024-02-01T00:00:00Z"}
+	txJSON, _ := json.Marshal(tx)
+
+	startKey := "h3~time~id\x00862a1072fffffff\x00"
+	endKey := "h3~time~id\x00862a1072fffffff" + string(utf8.MaxRune) + "\x00"
+	resultKey := "h3~time~id\x00862a1072fffffff\x002024-02-01T00:00:00Z\x00TX-1\x00"
+
+	stub.On("CreateCompositeKey", "h3~time~id", []string{"862a1072fffffff"}).Return(startKey, nil).Once()
+	stub.On("CreateCompositeKey", "h3~time~id", []string{"862a1072fffffff" + string(utf8.MaxRune)}).Return(endKey, nil).Once()
+
+	iterator := &mockStateQueryIterator{results: []*queryresultKV{{Key: resultKey}}}
+	metadata := &peer.QueryResponseMetadata{FetchedRecordsCount: 1, Bookmark: ""}
+	stub.On("GetStateByRangeWithPagination", startKey, endKey, int32(5), "").Return(iterator, metadata, nil).Once()
+	stub.On("SplitCompositeKey", resultKey).Return("h3~time~id", []string{"862a1072fffffff", "2024-02-01T00:00:00Z", "TX-1"}, nil).Once()
+	stub.On("GetState", "TX-1").Return(txJSON, nil).Once()
+
+	result, err := gc.QueryByH3Paginated(ctx, "862a1072fffffff", 5, "", "", "")
+	assert.NoError(t, err)
+	assert.Len(t, result.Transactions, 1)
+
+	stub.AssertExpectations(t)
+}
@@ -0,0 +1,140 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Chaincode Events
+//
+// SDK clients and block processors subscribe to SiteEvent instead of
+// polling. Fabric allows only one event per transaction, so when a single
+// transaction produces several payloads (e.g. CreateSite flagging a
+// recurrence) they are batched into one envelope rather than dropped.
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// siteEventName is the Fabric event name every SiteEvent envelope is
+// published under; clients distinguish payloads via SiteEvent.Type.
+const siteEventName = "SiteEvent"
+
+// siteEventVersion is bumped whenever the envelope or a payload shape
+// changes in a way that is not backwards compatible for listeners.
+const siteEventVersion = 1
+
+// Event type discriminators. "BATCH" marks an envelope carrying more than
+// one payload entry (see eventEntry).
+const (
+	EventSiteDetected       = "SiteDetected"
+	EventStatusChanged      = "StatusChanged"
+	EventEvidenceAttached   = "EvidenceAttached"
+	EventEvidenceSealed     = "EvidenceSealed"
+	EventRecurrenceDetected = "RecurrenceDetected"
+	EventBatch              = "BATCH"
+)
+
+// SiteEvent is the versioned envelope published by CreateSite, UpdateStatus,
+// AttachEvidence and RegisterStorageDeal. AttachPrivateNote emits no event.
+type SiteEvent struct {
+	Version   int             `json:"version"`
+	Type      string          `json:"type"`
+	SiteID    string          `json:"siteId"`
+	Payload   json.RawMessage `json:"payload"`
+	EmittedAt string          `json:"emittedAt"`
+}
+
+// eventEntry pairs a payload with its type discriminator so multiple
+// payloads can be batched into a single envelope's Payload field.
+type eventEntry struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// SiteDetected is emitted when a new mining site is recorded.
+type SiteDetected struct {
+	SiteID       string  `json:"siteId"`
+	SiteCode     string  `json:"siteCode"`
+	Region       string  `json:"region"`
+	Department   string  `json:"department"`
+	AreaHa       float64 `json:"areaHa"`
+	ConfidenceAI float64 `json:"confidenceAI"`
+}
+
+// StatusChanged is emitted whenever a site transitions between statuses.
+type StatusChanged struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	By   string `json:"by"`
+}
+
+// EvidenceAttached is emitted when an evidence bundle is attached to a site.
+type EvidenceAttached struct {
+	CID       string `json:"cid"`
+	SizeBytes int64  `json:"sizeBytes"`
+}
+
+// EvidenceDealSealed is emitted when an off-chain oracle confirms a
+// Filecoin storage deal is active for a previously-attached evidence bundle.
+type EvidenceDealSealed struct {
+	CID        string `json:"cid"`
+	DealID     string `json:"dealId"`
+	ProviderID string `json:"providerId"`
+}
+
+// RecurrenceDetected is emitted when a newly-created site is matched to a
+// previously-dismantled one by polygon overlap.
+type RecurrenceDetected struct {
+	OriginalID string  `json:"originalId"`
+	NewID      string  `json:"newId"`
+	IoU        float64 `json:"iou"`
+}
+
+// newEventEntry marshals a typed payload into an eventEntry, returning an
+// error if given a value that cannot be JSON-marshaled.
+func newEventEntry(eventType string, payload interface{}) (eventEntry, error) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return eventEntry{}, fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+	return eventEntry{Type: eventType, Payload: payloadJSON}, nil
+}
+
+// emitSiteEvent publishes a SiteEvent envelope for the given site, batching
+// multiple entries into a single "BATCH" envelope when more than one is
+// given since Fabric only allows one SetEvent call per transaction.
+func emitSiteEvent(ctx contractapi.TransactionContextInterface, siteID string, entries ...eventEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	event := SiteEvent{
+		Version:   siteEventVersion,
+		SiteID:    siteID,
+		EmittedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	if len(entries) == 1 {
+		event.Type = entries[0].Type
+		event.Payload = entries[0].Payload
+	} else {
+		batchJSON, err := json.Marshal(entries)
+		if err != nil {
+			return fmt.Errorf("failed to marshal batched event payloads: %w", err)
+		}
+		event.Type = EventBatch
+		event.Payload = batchJSON
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal site event: %w", err)
+	}
+
+	if err := ctx.GetStub().SetEvent(siteEventName, eventJSON); err != nil {
+		return fmt.Errorf("failed to emit site event: %w", err)
+	}
+
+	return nil
+}
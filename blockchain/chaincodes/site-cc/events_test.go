@@ -0,0 +1,75 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Chaincode Events Unit Tests
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEmitSiteEvent_SingleEntry(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+
+	var captured []byte
+	stub.On("SetEvent", siteEventName, mock.AnythingOfType("[]uint8")).
+		Run(func(args mock.Arguments) { captured = args.Get(1).([]byte) }).
+		Return(nil).Once()
+
+	entry, err := newEventEntry(EventSiteDetected, SiteDetected{SiteID: "SITE-001"})
+	assert.NoError(t, err)
+
+	err = emitSiteEvent(ctx, "SITE-001", entry)
+	assert.NoError(t, err)
+
+	var event SiteEvent
+	assert.NoError(t, json.Unmarshal(captured, &event))
+	assert.Equal(t, EventSiteDetected, event.Type)
+	assert.Equal(t, "SITE-001", event.SiteID)
+
+	stub.AssertExpectations(t)
+}
+
+func TestEmitSiteEvent_BatchesMultipleEntries(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+
+	var captured []byte
+	stub.On("SetEvent", siteEventName, mock.AnythingOfType("[]uint8")).
+		Run(func(args mock.Arguments) { captured = args.Get(1).([]byte) }).
+		Return(nil).Once()
+
+	detected, err := newEventEntry(EventSiteDetected, SiteDetected{SiteID: "SITE-NEW"})
+	assert.NoError(t, err)
+	recurrence, err := newEventEntry(EventRecurrenceDetected, RecurrenceDetected{OriginalID: "SITE-OLD", NewID: "SITE-NEW", IoU: 0.5})
+	assert.NoError(t, err)
+
+	err = emitSiteEvent(ctx, "SITE-NEW", detected, recurrence)
+	assert.NoError(t, err)
+
+	var event SiteEvent
+	assert.NoError(t, json.Unmarshal(captured, &event))
+	assert.Equal(t, EventBatch, event.Type)
+
+	var batched []eventEntry
+	assert.NoError(t, json.Unmarshal(event.Payload, &batched))
+	assert.Len(t, batched, 2)
+	assert.Equal(t, EventSiteDetected, batched[0].Type)
+	assert.Equal(t, EventRecurrenceDetected, batched[1].Type)
+
+	stub.AssertExpectations(t)
+}
+
+func TestEmitSiteEvent_NoEntriesNoEvent(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+
+	err := emitSiteEvent(ctx, "SITE-001")
+	assert.NoError(t, err)
+
+	stub.AssertExpectations(t)
+}
@@ -0,0 +1,312 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Evidence Smart Contract
+//
+// Tracks evidence bundles (GeoTIFFs, PDFs, AI detection reports) attached to
+// a mining site by IPFS CID, and the Filecoin storage-deal lifecycle that
+// preserves them long-term once sealed, mirroring the deal-state model
+// exposed by Lotus' storage market APIs.
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Evidence deal states.
+const (
+	EvidencePending = "PENDING"
+	EvidenceSealed  = "SEALED"
+	EvidenceExpired = "EXPIRED"
+)
+
+// cidv1Pattern matches a CIDv1 encoded in base32 (the default Lotus/IPFS
+// textual form) using either the dag-pb ("bafybei...") or raw ("bafkrei...")
+// multicodec prefix. This is intentionally shallow validation: a full
+// multibase/multicodec/multihash decode would pull in go-cid and its
+// dependency tree, which is more weight than a chaincode image should carry.
+var cidv1Pattern = regexp.MustCompile(`^(bafybe|bafkre)[a-z2-7]{52,}$`)
+
+// EvidenceManifest represents an evidence bundle attached to a mining site
+// and, once sealed, the Filecoin storage deal preserving it.
+type EvidenceManifest struct {
+	SiteID     string `json:"siteId"`
+	CID        string `json:"cid"`
+	SizeBytes  int64  `json:"sizeBytes"`
+	SHA256     string `json:"sha256"`
+	State      string `json:"state"`
+	DealID     string `json:"dealId,omitempty"`
+	ProviderID string `json:"providerId,omitempty"`
+	StartEpoch int64  `json:"startEpoch,omitempty"`
+	EndEpoch   int64  `json:"endEpoch,omitempty"`
+	AttachedAt string `json:"attachedAt"`
+	UpdatedAt  string `json:"updatedAt"`
+}
+
+// EvidenceContract provides functions for attaching evidence bundles to a
+// mining site and tracking their Filecoin storage-deal state.
+type EvidenceContract struct {
+	contractapi.Contract
+}
+
+// evidenceKey builds the primary composite key under which a manifest is
+// stored: evidence~site~cid.
+func evidenceKey(ctx contractapi.TransactionContextInterface, siteID, cid string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey("evidence~site~cid", []string{siteID, cid})
+	if err != nil {
+		return "", fmt.Errorf("failed to create evidence key: %w", err)
+	}
+	return key, nil
+}
+
+// evidenceStatusKey builds the secondary composite key used to look up
+// evidence manifests by deal state: evidence~status~cid.
+func evidenceStatusKey(ctx contractapi.TransactionContextInterface, state, cid string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey("evidence~status~cid", []string{state, cid})
+	if err != nil {
+		return "", fmt.Errorf("failed to create evidence status key: %w", err)
+	}
+	return key, nil
+}
+
+// ---------------------------------------------------------------------------
+// AttachEvidence records that an evidence bundle (already pinned on IPFS)
+// belongs to a mining site, and updates the site's IPFSCid pointer to it.
+// ---------------------------------------------------------------------------
+func (ec *EvidenceContract) AttachEvidence(
+	ctx contractapi.TransactionContextInterface,
+	siteID string,
+	cid string,
+	sizeBytes int64,
+	sha256 string,
+) error {
+	if _, err := requireRole(ctx, RoleAIPipeline); err != nil {
+		return err
+	}
+
+	if !cidv1Pattern.MatchString(cid) {
+		return fmt.Errorf("cid %s is not a valid CIDv1 (dag-pb or raw)", cid)
+	}
+	if sizeBytes <= 0 {
+		return fmt.Errorf("sizeBytes must be positive: %d", sizeBytes)
+	}
+
+	siteJSON, err := ctx.GetStub().GetState(siteID)
+	if err != nil {
+		return fmt.Errorf("failed to read site %s: %w", siteID, err)
+	}
+	if siteJSON == nil {
+		return fmt.Errorf("site %s does not exist", siteID)
+	}
+
+	key, err := evidenceKey(ctx, siteID, cid)
+	if err != nil {
+		return err
+	}
+	existing, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read evidence manifest: %w", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("evidence %s is already attached to site %s", cid, siteID)
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	manifest := EvidenceManifest{
+		SiteID:     siteID,
+		CID:        cid,
+		SizeBytes:  sizeBytes,
+		SHA256:     sha256,
+		State:      EvidencePending,
+		AttachedAt: now,
+		UpdatedAt:  now,
+	}
+
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal evidence manifest: %w", err)
+	}
+	if err := ctx.GetStub().PutState(key, manifestJSON); err != nil {
+		return fmt.Errorf("failed to put evidence manifest: %w", err)
+	}
+
+	statusKey, err := evidenceStatusKey(ctx, EvidencePending, cid)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(statusKey, []byte{0x00}); err != nil {
+		return fmt.Errorf("failed to put evidence status key: %w", err)
+	}
+
+	var site MiningSite
+	if err := json.Unmarshal(siteJSON, &site); err != nil {
+		return fmt.Errorf("failed to unmarshal site: %w", err)
+	}
+	site.IPFSCid = cid
+	site.UpdatedAt = now
+
+	updatedSiteJSON, err := json.Marshal(site)
+	if err != nil {
+		return fmt.Errorf("failed to marshal site: %w", err)
+	}
+	if err := ctx.GetStub().PutState(siteID, updatedSiteJSON); err != nil {
+		return fmt.Errorf("failed to put updated site: %w", err)
+	}
+
+	attachedEntry, err := newEventEntry(EventEvidenceAttached, EvidenceAttached{
+		CID:       cid,
+		SizeBytes: sizeBytes,
+	})
+	if err != nil {
+		return err
+	}
+
+	return emitSiteEvent(ctx, siteID, attachedEntry)
+}
+
+// ---------------------------------------------------------------------------
+// RegisterStorageDeal records that an off-chain oracle observed a Filecoin
+// storage deal become active for a previously-attached evidence bundle,
+// transitioning it from PENDING to SEALED.
+// ---------------------------------------------------------------------------
+func (ec *EvidenceContract) RegisterStorageDeal(
+	ctx contractapi.TransactionContextInterface,
+	siteID string,
+	cid string,
+	dealID string,
+	providerID string,
+	startEpoch int64,
+	endEpoch int64,
+) error {
+	if _, err := requireRole(ctx, RoleOracle); err != nil {
+		return err
+	}
+
+	if endEpoch <= startEpoch {
+		return fmt.Errorf("endEpoch %d must be after startEpoch %d", endEpoch, startEpoch)
+	}
+
+	key, err := evidenceKey(ctx, siteID, cid)
+	if err != nil {
+		return err
+	}
+	manifestJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read evidence manifest: %w", err)
+	}
+	if manifestJSON == nil {
+		return fmt.Errorf("no evidence %s attached to site %s", cid, siteID)
+	}
+
+	var manifest EvidenceManifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return fmt.Errorf("failed to unmarshal evidence manifest: %w", err)
+	}
+
+	oldStatusKey, err := evidenceStatusKey(ctx, manifest.State, cid)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().DelState(oldStatusKey); err != nil {
+		return fmt.Errorf("failed to delete old evidence status key: %w", err)
+	}
+
+	manifest.State = EvidenceSealed
+	manifest.DealID = dealID
+	manifest.ProviderID = providerID
+	manifest.StartEpoch = startEpoch
+	manifest.EndEpoch = endEpoch
+	manifest.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	updatedJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal evidence manifest: %w", err)
+	}
+	if err := ctx.GetStub().PutState(key, updatedJSON); err != nil {
+		return fmt.Errorf("failed to put evidence manifest: %w", err)
+	}
+
+	newStatusKey, err := evidenceStatusKey(ctx, EvidenceSealed, cid)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(newStatusKey, []byte{0x00}); err != nil {
+		return fmt.Errorf("failed to put evidence status key: %w", err)
+	}
+
+	sealedEntry, err := newEventEntry(EventEvidenceSealed, EvidenceDealSealed{
+		CID:        cid,
+		DealID:     dealID,
+		ProviderID: providerID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return emitSiteEvent(ctx, siteID, sealedEntry)
+}
+
+// ---------------------------------------------------------------------------
+// GetEvidenceStatus returns every evidence manifest attached to a site,
+// along with its current Filecoin deal state.
+// ---------------------------------------------------------------------------
+func (ec *EvidenceContract) GetEvidenceStatus(
+	ctx contractapi.TransactionContextInterface,
+	siteID string,
+) ([]*EvidenceManifest, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("evidence~site~cid", []string{siteID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get evidence for site %s: %w", siteID, err)
+	}
+	defer iterator.Close()
+
+	var manifests []*EvidenceManifest
+
+	for iterator.HasNext() {
+		responseRange, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate evidence manifests: %w", err)
+		}
+
+		var manifest EvidenceManifest
+		if err := json.Unmarshal(responseRange.Value, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal evidence manifest: %w", err)
+		}
+		manifests = append(manifests, &manifest)
+	}
+
+	return manifests, nil
+}
+
+// hasSealedEvidence reports whether a site has at least one evidence
+// manifest in the SEALED state, used by SiteContract.UpdateStatus to gate
+// the CONFIRMED transition.
+func hasSealedEvidence(ctx contractapi.TransactionContextInterface, siteID string) (bool, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("evidence~site~cid", []string{siteID})
+	if err != nil {
+		return false, fmt.Errorf("failed to get evidence for site %s: %w", siteID, err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		responseRange, err := iterator.Next()
+		if err != nil {
+			return false, fmt.Errorf("failed to iterate evidence manifests: %w", err)
+		}
+
+		var manifest EvidenceManifest
+		if err := json.Unmarshal(responseRange.Value, &manifest); err != nil {
+			return false, fmt.Errorf("failed to unmarshal evidence manifest: %w", err)
+		}
+		if manifest.State == EvidenceSealed {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
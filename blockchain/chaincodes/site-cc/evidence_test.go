@@ -0,0 +1,194 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Evidence Smart Contract Unit Tests
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const validCID = "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+
+// mockStateQueryIterator is a minimal in-memory implementation of
+// shim.StateQueryIteratorInterface used to exercise composite-key range
+// queries without a real ledger.
+type mockStateQueryIterator struct {
+	shim.StateQueryIteratorInterface
+	results []*queryresultKV
+	pos     int
+}
+
+type queryresultKV struct {
+	Key   string
+	Value []byte
+}
+
+func (m *mockStateQueryIterator) HasNext() bool {
+	return m.pos < len(m.results)
+}
+
+func (m *mockStateQueryIterator) Next() (*queryresult.KV, error) {
+	kv := m.results[m.pos]
+	m.pos++
+	return &queryresult.KV{Key: kv.Key, Value: kv.Value}, nil
+}
+
+func (m *mockStateQueryIterator) Close() error {
+	return nil
+}
+
+func TestAttachEvidence(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("AIPipelineMSP", "pipeline-01", RoleAIPipeline)}
+	ec := new(EvidenceContract)
+
+	siteID := "SITE-001"
+	site := MiningSite{ID: siteID, Status: "DETECTED"}
+	siteJSON, _ := json.Marshal(site)
+
+	evidenceKey := "evidence~site~cid\x00SITE-001\x00" + validCID + "\x00"
+	statusKey := "evidence~status~cid\x00PENDING\x00" + validCID + "\x00"
+
+	stub.On("GetState", siteID).Return(siteJSON, nil).Once()
+	stub.On("CreateCompositeKey", "evidence~site~cid", []string{siteID, validCID}).Return(evidenceKey, nil).Once()
+	stub.On("GetState", evidenceKey).Return(nil, nil).Once()
+	stub.On("PutState", evidenceKey, mock.AnythingOfType("[]uint8")).Return(nil).Once()
+	stub.On("CreateCompositeKey", "evidence~status~cid", []string{"PENDING", validCID}).Return(statusKey, nil).Once()
+	stub.On("PutState", statusKey, []byte{0x00}).Return(nil).Once()
+	stub.On("PutState", siteID, mock.AnythingOfType("[]uint8")).Return(nil).Once()
+	stub.On("SetEvent", siteEventName, mock.AnythingOfType("[]uint8")).Return(nil).Once()
+
+	err := ec.AttachEvidence(ctx, siteID, validCID, 1024, "deadbeef")
+	assert.NoError(t, err)
+
+	var manifest EvidenceManifest
+	assert.NoError(t, json.Unmarshal(stub.state[evidenceKey], &manifest))
+	assert.Equal(t, "PENDING", manifest.State)
+	assert.Equal(t, validCID, manifest.CID)
+
+	var updatedSite MiningSite
+	assert.NoError(t, json.Unmarshal(stub.state[siteID], &updatedSite))
+	assert.Equal(t, validCID, updatedSite.IPFSCid)
+
+	stub.AssertExpectations(t)
+}
+
+func TestAttachEvidence_InvalidCID(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("AIPipelineMSP", "pipeline-01", RoleAIPipeline)}
+	ec := new(EvidenceContract)
+
+	err := ec.AttachEvidence(ctx, "SITE-001", "not-a-cid", 1024, "deadbeef")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not a valid CIDv1")
+}
+
+func TestAttachEvidence_SiteNotFound(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("AIPipelineMSP", "pipeline-01", RoleAIPipeline)}
+	ec := new(EvidenceContract)
+
+	stub.On("GetState", "NONEXISTENT").Return(nil, nil).Once()
+
+	err := ec.AttachEvidence(ctx, "NONEXISTENT", validCID, 1024, "deadbeef")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "does not exist")
+
+	stub.AssertExpectations(t)
+}
+
+func TestAttachEvidence_WrongRole(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("InspectorMSP", "inspector-01", RoleInspector)}
+	ec := new(EvidenceContract)
+
+	err := ec.AttachEvidence(ctx, "SITE-001", validCID, 1024, "deadbeef")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not authorized")
+
+	stub.AssertExpectations(t)
+}
+
+func TestRegisterStorageDeal(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("OracleMSP", "filecoin-oracle", RoleOracle)}
+	ec := new(EvidenceContract)
+
+	siteID := "SITE-001"
+	evidenceKey := "evidence~site~cid\x00SITE-001\x00" + validCID + "\x00"
+	pendingStatusKey := "evidence~status~cid\x00PENDING\x00" + validCID + "\x00"
+	sealedStatusKey := "evidence~status~cid\x00SEALED\x00" + validCID + "\x00"
+
+	manifest := EvidenceManifest{SiteID: siteID, CID: validCID, State: EvidencePending}
+	manifestJSON, _ := json.Marshal(manifest)
+
+	stub.On("CreateCompositeKey", "evidence~site~cid", []string{siteID, validCID}).Return(evidenceKey, nil).Once()
+	stub.On("GetState", evidenceKey).Return(manifestJSON, nil).Once()
+	stub.On("CreateCompositeKey", "evidence~status~cid", []string{"PENDING", validCID}).Return(pendingStatusKey, nil).Once()
+	stub.On("DelState", pendingStatusKey).Return(nil).Once()
+	stub.On("PutState", evidenceKey, mock.AnythingOfType("[]uint8")).Return(nil).Once()
+	stub.On("CreateCompositeKey", "evidence~status~cid", []string{"SEALED", validCID}).Return(sealedStatusKey, nil).Once()
+	stub.On("PutState", sealedStatusKey, []byte{0x00}).Return(nil).Once()
+	stub.On("SetEvent", siteEventName, mock.AnythingOfType("[]uint8")).Return(nil).Once()
+
+	err := ec.RegisterStorageDeal(ctx, siteID, validCID, "deal-1", "f01000", 100, 2000)
+	assert.NoError(t, err)
+
+	var updated EvidenceManifest
+	assert.NoError(t, json.Unmarshal(stub.state[evidenceKey], &updated))
+	assert.Equal(t, "SEALED", updated.State)
+	assert.Equal(t, "deal-1", updated.DealID)
+
+	stub.AssertExpectations(t)
+}
+
+func TestRegisterStorageDeal_InvalidEpochRange(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("OracleMSP", "filecoin-oracle", RoleOracle)}
+	ec := new(EvidenceContract)
+
+	err := ec.RegisterStorageDeal(ctx, "SITE-001", validCID, "deal-1", "f01000", 2000, 100)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "must be after")
+}
+
+func TestRegisterStorageDeal_WrongRole(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("AIPipelineMSP", "pipeline-01", RoleAIPipeline)}
+	ec := new(EvidenceContract)
+
+	err := ec.RegisterStorageDeal(ctx, "SITE-001", validCID, "deal-1", "f01000", 100, 2000)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not authorized")
+
+	stub.AssertExpectations(t)
+}
+
+func TestGetEvidenceStatus(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	ec := new(EvidenceContract)
+
+	siteID := "SITE-001"
+	manifest := EvidenceManifest{SiteID: siteID, CID: validCID, State: EvidenceSealed}
+	manifestJSON, _ := json.Marshal(manifest)
+
+	iterator := &mockStateQueryIterator{results: []*queryresultKV{
+		{Key: "evidence~site~cid\x00SITE-001\x00" + validCID + "\x00", Value: manifestJSON},
+	}}
+
+	stub.On("GetStateByPartialCompositeKey", "evidence~site~cid", []string{siteID}).Return(iterator, nil).Once()
+
+	manifests, err := ec.GetEvidenceStatus(ctx, siteID)
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 1)
+	assert.Equal(t, EvidenceSealed, manifests[0].State)
+
+	stub.AssertExpectations(t)
+}
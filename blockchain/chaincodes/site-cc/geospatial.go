@@ -0,0 +1,472 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Geospatial Recurrence Detection
+//
+// Decides when a newly-detected site is a recurrence of a previously
+// dismantled one by comparing polygon overlap. The WKT parser and geohash
+// indexer are intentionally minimal (regex extraction, pure-Go bit
+// interleaving) rather than pulling in a full GIS stack, since chaincode
+// images need to stay small.
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// recurrenceIoUThreshold is the minimum intersection-over-union ratio at
+// which a newly-created site is considered a recurrence of a dismantled one.
+const recurrenceIoUThreshold = 0.30
+
+// geohashPrecision is the number of base32 characters used to index a site's
+// bbox centroid. Precision 6 resolves to roughly 1.2km x 0.6km cells, which
+// is coarse enough to group recurring sites while still being cheap to scan.
+const geohashPrecision = 6
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Point is a single WKT ring vertex.
+type Point struct {
+	Lon float64 `json:"lon"`
+	Lat float64 `json:"lat"`
+}
+
+// wktRingPattern extracts the coordinate lists out of a POLYGON or
+// MULTIPOLYGON WKT string. It matches every innermost parenthesised list, so
+// inner rings (holes) are extracted as well as outer rings; the overlap
+// heuristic only compares the first ring found.
+var wktRingPattern = regexp.MustCompile(`\(([-0-9.,\s]+)\)`)
+
+// parseWKTRings extracts every ring of coordinates found in a POLYGON or
+// MULTIPOLYGON WKT string into [][]Point. It does not validate WKT grammar
+// beyond what is needed to pull out numbers.
+func parseWKTRings(wkt string) ([][]Point, error) {
+	upper := strings.ToUpper(strings.TrimSpace(wkt))
+	if !strings.HasPrefix(upper, "POLYGON") && !strings.HasPrefix(upper, "MULTIPOLYGON") {
+		return nil, fmt.Errorf("unsupported WKT geometry type (only POLYGON/MULTIPOLYGON are supported)")
+	}
+
+	matches := wktRingPattern.FindAllStringSubmatch(wkt, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no coordinate rings found in WKT: %s", wkt)
+	}
+
+	var rings [][]Point
+	for _, m := range matches {
+		coordPairs := strings.Split(m[1], ",")
+		var ring []Point
+		for _, pair := range coordPairs {
+			fields := strings.Fields(strings.TrimSpace(pair))
+			if len(fields) != 2 {
+				continue
+			}
+			lon, err := strconv.ParseFloat(fields[0], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid longitude in WKT: %w", err)
+			}
+			lat, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid latitude in WKT: %w", err)
+			}
+			ring = append(ring, Point{Lon: lon, Lat: lat})
+		}
+		if len(ring) >= 3 {
+			rings = append(rings, ring)
+		}
+	}
+
+	if len(rings) == 0 {
+		return nil, fmt.Errorf("no usable polygon ring found in WKT: %s", wkt)
+	}
+
+	return rings, nil
+}
+
+// boundingBox returns the axis-aligned bbox (minLon, minLat, maxLon, maxLat)
+// enclosing every ring.
+func boundingBox(rings [][]Point) (minLon, minLat, maxLon, maxLat float64) {
+	first := true
+	for _, ring := range rings {
+		for _, p := range ring {
+			if first {
+				minLon, maxLon = p.Lon, p.Lon
+				minLat, maxLat = p.Lat, p.Lat
+				first = false
+				continue
+			}
+			if p.Lon < minLon {
+				minLon = p.Lon
+			}
+			if p.Lon > maxLon {
+				maxLon = p.Lon
+			}
+			if p.Lat < minLat {
+				minLat = p.Lat
+			}
+			if p.Lat > maxLat {
+				maxLat = p.Lat
+			}
+		}
+	}
+	return minLon, minLat, maxLon, maxLat
+}
+
+// encodeGeohash implements the standard base32 geohash encoding of a
+// lon/lat pair to the given precision.
+func encodeGeohash(lon, lat float64, precision int) string {
+	latRange := [2]float64{-90, 90}
+	lonRange := [2]float64{-180, 180}
+
+	var hash strings.Builder
+	bit, ch, evenBit := 0, 0, true
+
+	for hash.Len() < precision {
+		if evenBit {
+			mid := (lonRange[0] + lonRange[1]) / 2
+			if lon >= mid {
+				ch |= 1 << uint(4-bit)
+				lonRange[0] = mid
+			} else {
+				lonRange[1] = mid
+			}
+		} else {
+			mid := (latRange[0] + latRange[1]) / 2
+			if lat >= mid {
+				ch |= 1 << uint(4-bit)
+				latRange[0] = mid
+			} else {
+				latRange[1] = mid
+			}
+		}
+		evenBit = !evenBit
+
+		if bit < 4 {
+			bit++
+		} else {
+			hash.WriteByte(geohashBase32[ch])
+			bit, ch = 0, 0
+		}
+	}
+
+	return hash.String()
+}
+
+// indexSiteGeohash computes the bbox centroid of a site's geometry and
+// indexes it under a geohash~id composite key so nearby sites can be found
+// with a prefix scan.
+func indexSiteGeohash(ctx contractapi.TransactionContextInterface, id string, geometryWKT string) error {
+	rings, err := parseWKTRings(geometryWKT)
+	if err != nil {
+		return fmt.Errorf("failed to parse geometry for site %s: %w", id, err)
+	}
+
+	minLon, minLat, maxLon, maxLat := boundingBox(rings)
+	centroidLon := (minLon + maxLon) / 2
+	centroidLat := (minLat + maxLat) / 2
+	geohash := encodeGeohash(centroidLon, centroidLat, geohashPrecision)
+
+	key, err := ctx.GetStub().CreateCompositeKey("geohash~id", []string{geohash, id})
+	if err != nil {
+		return fmt.Errorf("failed to create geohash key: %w", err)
+	}
+	if err := ctx.GetStub().PutState(key, []byte{0x00}); err != nil {
+		return fmt.Errorf("failed to put geohash key: %w", err)
+	}
+
+	return nil
+}
+
+// clipPolygon runs Sutherland-Hodgman clipping of subject against the
+// convex-ish clip ring, returning the intersection ring. This is sufficient
+// for the simple polygons produced by the AI detection pipeline and avoids
+// pulling in a full computational-geometry library. isLeft assumes the clip
+// ring winds counter-clockwise, so clip is normalized to that orientation
+// first - WKT polygons coming out of real GIS tooling are just as often
+// wound clockwise, and clipping against a clockwise ring as-is would treat
+// every vertex as outside and collapse the intersection to nothing.
+func clipPolygon(subject, clip []Point) []Point {
+	clip = normalizeCCW(clip)
+	output := subject
+	for i := range clip {
+		if len(output) == 0 {
+			break
+		}
+		a := clip[i]
+		b := clip[(i+1)%len(clip)]
+
+		input := output
+		output = nil
+
+		for j := range input {
+			current := input[j]
+			prev := input[(j-1+len(input))%len(input)]
+
+			currentInside := isLeft(a, b, current)
+			prevInside := isLeft(a, b, prev)
+
+			if currentInside {
+				if !prevInside {
+					output = append(output, lineIntersection(prev, current, a, b))
+				}
+				output = append(output, current)
+			} else if prevInside {
+				output = append(output, lineIntersection(prev, current, a, b))
+			}
+		}
+	}
+	return output
+}
+
+// isLeft reports whether point p is on the left (inside) side of directed
+// edge a->b, which is the convention Sutherland-Hodgman clips against.
+func isLeft(a, b, p Point) bool {
+	return (b.Lon-a.Lon)*(p.Lat-a.Lat)-(b.Lat-a.Lat)*(p.Lon-a.Lon) >= 0
+}
+
+// lineIntersection returns the intersection point of segment p1-p2 with the
+// infinite line through a-b.
+func lineIntersection(p1, p2, a, b Point) Point {
+	a1 := b.Lat - a.Lat
+	b1 := a.Lon - b.Lon
+	c1 := a1*a.Lon + b1*a.Lat
+
+	a2 := p2.Lat - p1.Lat
+	b2 := p1.Lon - p2.Lon
+	c2 := a2*p1.Lon + b2*p1.Lat
+
+	det := a1*b2 - a2*b1
+	if det == 0 {
+		return p2
+	}
+	return Point{
+		Lon: (b2*c1 - b1*c2) / det,
+		Lat: (a1*c2 - a2*c1) / det,
+	}
+}
+
+// signedRingArea computes the shoelace area of a ring, whose sign reflects
+// winding order: positive for counter-clockwise, negative for clockwise.
+func signedRingArea(ring []Point) float64 {
+	if len(ring) < 3 {
+		return 0
+	}
+	var sum float64
+	for i := range ring {
+		j := (i + 1) % len(ring)
+		sum += ring[i].Lon*ring[j].Lat - ring[j].Lon*ring[i].Lat
+	}
+	return sum / 2
+}
+
+// ringArea computes the unsigned shoelace area of a ring.
+func ringArea(ring []Point) float64 {
+	area := signedRingArea(ring)
+	if area < 0 {
+		area = -area
+	}
+	return area
+}
+
+// normalizeCCW reverses ring if it winds clockwise, so callers that rely on
+// a consistent winding order (clipPolygon's isLeft test) get one regardless
+// of how the source WKT happened to wind its vertices.
+func normalizeCCW(ring []Point) []Point {
+	if signedRingArea(ring) >= 0 {
+		return ring
+	}
+	reversed := make([]Point, len(ring))
+	for i, p := range ring {
+		reversed[len(ring)-1-i] = p
+	}
+	return reversed
+}
+
+// polygonIoU computes the intersection-over-union of the two polygons'
+// outer rings using Sutherland-Hodgman clipping.
+func polygonIoU(a, b []Point) float64 {
+	areaA := ringArea(a)
+	areaB := ringArea(b)
+	if areaA == 0 || areaB == 0 {
+		return 0
+	}
+
+	intersection := clipPolygon(a, b)
+	intersectionArea := ringArea(intersection)
+
+	union := areaA + areaB - intersectionArea
+	if union <= 0 {
+		return 0
+	}
+	return intersectionArea / union
+}
+
+// RecurrenceLink records that a newly-detected site was matched to a
+// previously-dismantled one by polygon overlap.
+type RecurrenceLink struct {
+	OriginalID string  `json:"originalId"`
+	NewID      string  `json:"newId"`
+	IoU        float64 `json:"iou"`
+	LinkedAt   string  `json:"linkedAt"`
+}
+
+// FindOverlappingDismantled looks for DISMANTLED sites whose polygon
+// overlaps the given geometry above recurrenceIoUThreshold, and for each
+// match found automatically transitions that site to RECURRED and records
+// the link. It returns the links created, if any. Only sites indexed under
+// the same precision-6 geohash cell as the geometry's bbox centroid are
+// considered, and only the first ring of each geometry is compared.
+func FindOverlappingDismantled(
+	ctx contractapi.TransactionContextInterface,
+	newSiteID string,
+	geometryWKT string,
+) ([]*RecurrenceLink, error) {
+	rings, err := parseWKTRings(geometryWKT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse geometry for site %s: %w", newSiteID, err)
+	}
+	newRing := rings[0]
+
+	minLon, minLat, maxLon, maxLat := boundingBox(rings)
+	centroidLon := (minLon + maxLon) / 2
+	centroidLat := (minLat + maxLat) / 2
+	geohash := encodeGeohash(centroidLon, centroidLat, geohashPrecision)
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("geohash~id", []string{geohash})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nearby sites by geohash %s: %w", geohash, err)
+	}
+	defer iterator.Close()
+
+	var links []*RecurrenceLink
+
+	for iterator.HasNext() {
+		responseRange, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate geohash index: %w", err)
+		}
+
+		_, parts, err := ctx.GetStub().SplitCompositeKey(responseRange.Key)
+		if err != nil || len(parts) < 2 {
+			continue
+		}
+		candidateID := parts[1]
+		if candidateID == newSiteID {
+			continue
+		}
+
+		candidateJSON, err := ctx.GetStub().GetState(candidateID)
+		if err != nil || candidateJSON == nil {
+			continue
+		}
+
+		var candidate MiningSite
+		if err := json.Unmarshal(candidateJSON, &candidate); err != nil {
+			continue
+		}
+		if candidate.Status != "DISMANTLED" {
+			continue
+		}
+
+		candidateRings, err := parseWKTRings(candidate.GeometryWKT)
+		if err != nil || len(candidateRings) == 0 {
+			continue
+		}
+
+		iou := polygonIoU(newRing, candidateRings[0])
+		if iou < recurrenceIoUThreshold {
+			continue
+		}
+
+		previousStatus := candidate.Status
+		if err := applyStatusTransition(ctx, &candidate, "RECURRED"); err != nil {
+			return nil, fmt.Errorf("failed to mark site %s as recurred: %w", candidateID, err)
+		}
+		if err := recordTransition(ctx, candidateID, previousStatus, "RECURRED", systemIdentity); err != nil {
+			return nil, fmt.Errorf("failed to record recurrence transition for site %s: %w", candidateID, err)
+		}
+
+		link := &RecurrenceLink{
+			OriginalID: candidateID,
+			NewID:      newSiteID,
+			IoU:        iou,
+			LinkedAt:   candidate.UpdatedAt,
+		}
+		linkJSON, err := json.Marshal(link)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal recurrence link: %w", err)
+		}
+
+		linkKey, err := ctx.GetStub().CreateCompositeKey("recurrence~original~new", []string{candidateID, newSiteID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create recurrence link key: %w", err)
+		}
+		if err := ctx.GetStub().PutState(linkKey, linkJSON); err != nil {
+			return nil, fmt.Errorf("failed to put recurrence link: %w", err)
+		}
+
+		reverseLinkKey, err := ctx.GetStub().CreateCompositeKey("recurrence~new~original", []string{newSiteID, candidateID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create reverse recurrence link key: %w", err)
+		}
+		if err := ctx.GetStub().PutState(reverseLinkKey, linkJSON); err != nil {
+			return nil, fmt.Errorf("failed to put reverse recurrence link: %w", err)
+		}
+
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+// GetRecurrenceHistory returns the chain of recurrence links in which the
+// given site appears, either as the original dismantled site or as a
+// newly-detected recurrence of one.
+func (sc *SiteContract) GetRecurrenceHistory(
+	ctx contractapi.TransactionContextInterface,
+	siteID string,
+) ([]*RecurrenceLink, error) {
+	var links []*RecurrenceLink
+
+	asOriginal, err := ctx.GetStub().GetStateByPartialCompositeKey("recurrence~original~new", []string{siteID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recurrence links for site %s: %w", siteID, err)
+	}
+	defer asOriginal.Close()
+
+	for asOriginal.HasNext() {
+		responseRange, err := asOriginal.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate recurrence links: %w", err)
+		}
+		var link RecurrenceLink
+		if err := json.Unmarshal(responseRange.Value, &link); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal recurrence link: %w", err)
+		}
+		links = append(links, &link)
+	}
+
+	asNew, err := ctx.GetStub().GetStateByPartialCompositeKey("recurrence~new~original", []string{siteID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recurrence links for site %s: %w", siteID, err)
+	}
+	defer asNew.Close()
+
+	for asNew.HasNext() {
+		responseRange, err := asNew.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate recurrence links: %w", err)
+		}
+		var link RecurrenceLink
+		if err := json.Unmarshal(responseRange.Value, &link); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal recurrence link: %w", err)
+		}
+		links = append(links, &link)
+	}
+
+	return links, nil
+}
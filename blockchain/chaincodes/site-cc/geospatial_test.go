@@ -0,0 +1,93 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Geospatial Recurrence Detection Unit Tests
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParseWKTRings_InvalidGeometry(t *testing.T) {
+	_, err := parseWKTRings("POINT(29.5 -10.5)")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported WKT geometry type")
+}
+
+func TestEncodeGeohash_Deterministic(t *testing.T) {
+	hash := encodeGeohash(29.55, -10.55, geohashPrecision)
+	assert.Equal(t, "kwj5br", hash)
+}
+
+func TestPolygonIoU_FullOverlap(t *testing.T) {
+	square := []Point{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	assert.InDelta(t, 1.0, polygonIoU(square, square), 1e-9)
+}
+
+func TestPolygonIoU_NoOverlap(t *testing.T) {
+	a := []Point{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	b := []Point{{10, 10}, {11, 10}, {11, 11}, {10, 11}}
+	assert.Equal(t, 0.0, polygonIoU(a, b))
+}
+
+func TestPolygonIoU_PartialOverlap(t *testing.T) {
+	a := []Point{{0, 0}, {2, 0}, {2, 2}, {0, 2}}
+	b := []Point{{1, 1}, {3, 1}, {3, 3}, {1, 3}}
+	// intersection is the [1,2]x[1,2] square (area 1), union = 4+4-1 = 7
+	assert.InDelta(t, 1.0/7.0, polygonIoU(a, b), 1e-9)
+}
+
+func TestFindOverlappingDismantled_TransitionsToRecurred(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+
+	newSiteID := "SITE-NEW"
+	newGeometry := "POLYGON((29.5 -10.5, 29.6 -10.5, 29.6 -10.6, 29.5 -10.6, 29.5 -10.5))"
+
+	dismantledID := "SITE-OLD"
+	dismantled := MiningSite{
+		ID:          dismantledID,
+		GeometryWKT: newGeometry,
+		Status:      "DISMANTLED",
+	}
+	dismantledJSON, _ := json.Marshal(dismantled)
+
+	geohashKey := "geohash~id\x00kwj5br\x00SITE-OLD\x00"
+	iterator := &mockStateQueryIterator{results: []*queryresultKV{
+		{Key: geohashKey},
+	}}
+
+	oldCompositeKey := "status~id\x00DISMANTLED\x00SITE-OLD\x00"
+	newCompositeKey := "status~id\x00RECURRED\x00SITE-OLD\x00"
+	linkKey := "recurrence~original~new\x00SITE-OLD\x00SITE-NEW\x00"
+	reverseLinkKey := "recurrence~new~original\x00SITE-NEW\x00SITE-OLD\x00"
+	transitionKey := "transition~site~ts\x00SITE-OLD\x002024-01-15T10:00:00Z\x00"
+
+	stub.On("GetStateByPartialCompositeKey", "geohash~id", []string{"kwj5br"}).Return(iterator, nil).Once()
+	stub.On("SplitCompositeKey", geohashKey).Return("geohash~id", []string{"kwj5br", dismantledID}, nil).Once()
+	stub.On("GetState", dismantledID).Return(dismantledJSON, nil).Once()
+	stub.On("CreateCompositeKey", "status~id", []string{"DISMANTLED", dismantledID}).Return(oldCompositeKey, nil).Once()
+	stub.On("DelState", oldCompositeKey).Return(nil).Once()
+	stub.On("PutState", dismantledID, mock.AnythingOfType("[]uint8")).Return(nil).Once()
+	stub.On("CreateCompositeKey", "status~id", []string{"RECURRED", dismantledID}).Return(newCompositeKey, nil).Once()
+	stub.On("PutState", newCompositeKey, []byte{0x00}).Return(nil).Once()
+	stub.On("GetTxID").Return("tx-1").Once()
+	stub.On("CreateCompositeKey", "transition~site~ts", mock.AnythingOfType("[]string")).Return(transitionKey, nil).Once()
+	stub.On("PutState", transitionKey, mock.AnythingOfType("[]uint8")).Return(nil).Once()
+	stub.On("CreateCompositeKey", "recurrence~original~new", []string{dismantledID, newSiteID}).Return(linkKey, nil).Once()
+	stub.On("PutState", linkKey, mock.AnythingOfType("[]uint8")).Return(nil).Once()
+	stub.On("CreateCompositeKey", "recurrence~new~original", []string{newSiteID, dismantledID}).Return(reverseLinkKey, nil).Once()
+	stub.On("PutState", reverseLinkKey, mock.AnythingOfType("[]uint8")).Return(nil).Once()
+
+	links, err := FindOverlappingDismantled(ctx, newSiteID, newGeometry)
+	assert.NoError(t, err)
+	assert.Len(t, links, 1)
+	assert.Equal(t, dismantledID, links[0].OriginalID)
+	assert.Equal(t, newSiteID, links[0].NewID)
+	assert.InDelta(t, 1.0, links[0].IoU, 1e-9)
+
+	stub.AssertExpectations(t)
+}
@@ -0,0 +1,86 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  MSP Identity and Role-Based Access Control
+//
+// createdBy/updatedBy used to be free-form strings, which let any client
+// claim any identity. Attribution is now derived from the caller's MSP
+// identity (MSPID + x509 CN + OU) instead, and the OU is treated as the
+// caller's role for gating status transitions.
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Roles recognized from a client certificate's OU attribute.
+const (
+	RoleAIPipeline = "AI_PIPELINE"
+	RoleInspector  = "INSPECTOR"
+	RoleSupervisor = "SUPERVISOR"
+	RoleMinistry   = "MINISTRY"
+	RoleOracle     = "ORACLE"
+)
+
+// Identity is the signing identity of the caller of a transaction, derived
+// from their MSP client identity rather than a self-declared parameter.
+type Identity struct {
+	MSPID      string `json:"mspId"`
+	CN         string `json:"cn"`
+	OU         string `json:"ou"`
+	CertSHA256 string `json:"certSha256"`
+}
+
+// Role returns the caller's role, which is taken to be the first OU entry
+// on their certificate.
+func (i *Identity) Role() string {
+	return i.OU
+}
+
+// callerIdentity resolves the Identity of the caller of a transaction from
+// ctx.GetClientIdentity(), rather than trusting a self-declared parameter.
+func callerIdentity(ctx contractapi.TransactionContextInterface) (*Identity, error) {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read caller MSPID: %w", err)
+	}
+
+	cert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read caller certificate: %w", err)
+	}
+
+	ou := ""
+	if len(cert.Subject.OrganizationalUnit) > 0 {
+		ou = cert.Subject.OrganizationalUnit[0]
+	}
+
+	certHash := sha256.Sum256(cert.Raw)
+
+	return &Identity{
+		MSPID:      mspID,
+		CN:         cert.Subject.CommonName,
+		OU:         ou,
+		CertSHA256: hex.EncodeToString(certHash[:]),
+	}, nil
+}
+
+// requireRole resolves the caller's identity and verifies their OU matches
+// one of the allowed roles for the transition being attempted.
+func requireRole(ctx contractapi.TransactionContextInterface, allowedRoles ...string) (*Identity, error) {
+	identity, err := callerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range allowedRoles {
+		if identity.Role() == role {
+			return identity, nil
+		}
+	}
+
+	return nil, fmt.Errorf("caller with OU %q is not authorized to perform this action (requires one of %v)", identity.Role(), allowedRoles)
+}
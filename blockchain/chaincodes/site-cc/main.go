@@ -11,8 +11,9 @@ import (
 
 func main() {
 	siteContract := new(SiteContract)
+	evidenceContract := new(EvidenceContract)
 
-	chaincode, err := contractapi.NewChaincode(siteContract)
+	chaincode, err := contractapi.NewChaincode(siteContract, evidenceContract)
 	if err != nil {
 		log.Fatalf("Error creating site-cc chaincode: %v", err)
 	}
@@ -0,0 +1,209 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Private Data Collections
+//
+// Inspector free-text notes, precise access-road GPS coordinates, suspect
+// names and photo CIDs are sensitive and must not live on the channel-wide
+// ledger. They are written to a Fabric private data collection (PDC)
+// instead; only a SHA-256 hash of the payload is committed on the public
+// ledger, so any party can later verify a disclosed note matches what was
+// originally attached without being able to read it themselves.
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// privateNoteTransientKey is the key under which a client passes the
+// MiningSitePrivate payload in the transaction's transient map, keeping it
+// out of the proposal, the transaction, and the public ledger.
+const privateNoteTransientKey = "note"
+
+// collectionMembers lists the MSPIDs authorized to read each known private
+// data collection. Membership here must mirror the collection's actual
+// endorsement/collection policy on the channel.
+var collectionMembers = map[string][]string{
+	"inspectorNotesCollection": {"MinistryMSP", "InspectorMSP"},
+}
+
+// MiningSitePrivate holds the sensitive detail of a mining site that never
+// leaves the private data collection it is written to.
+type MiningSitePrivate struct {
+	SiteID        string   `json:"siteId"`
+	Notes         string   `json:"notes"`
+	AccessRoadGPS []Point  `json:"accessRoadGps"`
+	SuspectNames  []string `json:"suspectNames"`
+	PhotoCIDs     []string `json:"photoCids"`
+}
+
+// PrivateNoteHash is the public-ledger integrity record for a private note:
+// just enough to verify a disclosed payload, never the payload itself.
+type PrivateNoteHash struct {
+	SiteID     string `json:"siteId"`
+	Collection string `json:"collection"`
+	SHA256     string `json:"sha256"`
+	AttachedAt string `json:"attachedAt"`
+}
+
+func isCollectionMember(mspID string, collection string) bool {
+	for _, member := range collectionMembers[collection] {
+		if member == mspID {
+			return true
+		}
+	}
+	return false
+}
+
+// privateNoteHashKey builds the composite key under which the public
+// integrity hash for a site's private note is stored.
+func privateNoteHashKey(ctx contractapi.TransactionContextInterface, siteID, collection string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey("notehash~site~collection", []string{siteID, collection})
+	if err != nil {
+		return "", fmt.Errorf("failed to create private note hash key: %w", err)
+	}
+	return key, nil
+}
+
+// ---------------------------------------------------------------------------
+// AttachPrivateNote writes a MiningSitePrivate payload, passed via the
+// transaction's transient data, to the given private data collection, and
+// commits only its SHA-256 hash to the public ledger.
+// ---------------------------------------------------------------------------
+func (ec *EvidenceContract) AttachPrivateNote(
+	ctx contractapi.TransactionContextInterface,
+	siteID string,
+	collection string,
+) error {
+	if _, err := requireRole(ctx, RoleInspector); err != nil {
+		return err
+	}
+
+	if _, ok := collectionMembers[collection]; !ok {
+		return fmt.Errorf("unknown private data collection %s", collection)
+	}
+
+	siteJSON, err := ctx.GetStub().GetState(siteID)
+	if err != nil {
+		return fmt.Errorf("failed to read site %s: %w", siteID, err)
+	}
+	if siteJSON == nil {
+		return fmt.Errorf("site %s does not exist", siteID)
+	}
+
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to read transient data: %w", err)
+	}
+	payload, ok := transient[privateNoteTransientKey]
+	if !ok {
+		return fmt.Errorf("transient data is missing the %q key", privateNoteTransientKey)
+	}
+
+	var note MiningSitePrivate
+	if err := json.Unmarshal(payload, &note); err != nil {
+		return fmt.Errorf("failed to unmarshal private note: %w", err)
+	}
+	note.SiteID = siteID
+
+	noteJSON, err := json.Marshal(note)
+	if err != nil {
+		return fmt.Errorf("failed to marshal private note: %w", err)
+	}
+
+	if err := ctx.GetStub().PutPrivateData(collection, siteID, noteJSON); err != nil {
+		return fmt.Errorf("failed to put private note: %w", err)
+	}
+
+	hash := sha256.Sum256(noteJSON)
+
+	hashRecord := PrivateNoteHash{
+		SiteID:     siteID,
+		Collection: collection,
+		SHA256:     hex.EncodeToString(hash[:]),
+		AttachedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	hashJSON, err := json.Marshal(hashRecord)
+	if err != nil {
+		return fmt.Errorf("failed to marshal private note hash: %w", err)
+	}
+
+	key, err := privateNoteHashKey(ctx, siteID, collection)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutState(key, hashJSON); err != nil {
+		return fmt.Errorf("failed to put private note hash: %w", err)
+	}
+
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// GetPrivateNote returns the private note for a site, provided the caller's
+// MSPID is a member of the collection's access policy.
+// ---------------------------------------------------------------------------
+func (ec *EvidenceContract) GetPrivateNote(
+	ctx contractapi.TransactionContextInterface,
+	siteID string,
+	collection string,
+) (*MiningSitePrivate, error) {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read caller MSPID: %w", err)
+	}
+	if !isCollectionMember(mspID, collection) {
+		return nil, fmt.Errorf("MSPID %s is not a member of collection %s", mspID, collection)
+	}
+
+	noteJSON, err := ctx.GetStub().GetPrivateData(collection, siteID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private note: %w", err)
+	}
+	if noteJSON == nil {
+		return nil, fmt.Errorf("no private note for site %s in collection %s", siteID, collection)
+	}
+
+	var note MiningSitePrivate
+	if err := json.Unmarshal(noteJSON, &note); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal private note: %w", err)
+	}
+
+	return &note, nil
+}
+
+// ---------------------------------------------------------------------------
+// GetPrivateNoteHash returns the public-ledger integrity hash committed for
+// a site's private note, so any party can verify a disclosed note matches
+// what was originally attached.
+// ---------------------------------------------------------------------------
+func (ec *EvidenceContract) GetPrivateNoteHash(
+	ctx contractapi.TransactionContextInterface,
+	siteID string,
+	collection string,
+) (*PrivateNoteHash, error) {
+	key, err := privateNoteHashKey(ctx, siteID, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	hashJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private note hash: %w", err)
+	}
+	if hashJSON == nil {
+		return nil, fmt.Errorf("no private note hash for site %s in collection %s", siteID, collection)
+	}
+
+	var hashRecord PrivateNoteHash
+	if err := json.Unmarshal(hashJSON, &hashRecord); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal private note hash: %w", err)
+	}
+
+	return &hashRecord, nil
+}
@@ -0,0 +1,124 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Private Data Collections Unit Tests
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testCollection = "inspectorNotesCollection"
+
+func TestAttachPrivateNote(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("InspectorMSP", "inspector-01", RoleInspector)}
+	ec := new(EvidenceContract)
+
+	siteID := "SITE-001"
+	site := MiningSite{ID: siteID}
+	siteJSON, _ := json.Marshal(site)
+
+	note := MiningSitePrivate{Notes: "suspects fled north", SuspectNames: []string{"J. Doe"}}
+	noteJSON, _ := json.Marshal(note)
+
+	hashKey := "notehash~site~collection\x00SITE-001\x00" + testCollection + "\x00"
+
+	stub.On("GetState", siteID).Return(siteJSON, nil).Once()
+	stub.On("GetTransient").Return(map[string][]byte{privateNoteTransientKey: noteJSON}, nil).Once()
+	stub.On("PutPrivateData", testCollection, siteID, mock.AnythingOfType("[]uint8")).Return(nil).Once()
+	stub.On("CreateCompositeKey", "notehash~site~collection", []string{siteID, testCollection}).Return(hashKey, nil).Once()
+	stub.On("PutState", hashKey, mock.AnythingOfType("[]uint8")).Return(nil).Once()
+
+	err := ec.AttachPrivateNote(ctx, siteID, testCollection)
+	assert.NoError(t, err)
+
+	var hashRecord PrivateNoteHash
+	assert.NoError(t, json.Unmarshal(stub.state[hashKey], &hashRecord))
+	assert.Equal(t, siteID, hashRecord.SiteID)
+	assert.NotEmpty(t, hashRecord.SHA256)
+
+	stub.AssertExpectations(t)
+}
+
+func TestAttachPrivateNote_UnknownCollection(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("InspectorMSP", "inspector-01", RoleInspector)}
+	ec := new(EvidenceContract)
+
+	err := ec.AttachPrivateNote(ctx, "SITE-001", "not-a-real-collection")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown private data collection")
+}
+
+func TestAttachPrivateNote_WrongRole(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("AIPipelineMSP", "pipeline-01", RoleAIPipeline)}
+	ec := new(EvidenceContract)
+
+	err := ec.AttachPrivateNote(ctx, "SITE-001", testCollection)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not authorized")
+
+	stub.AssertExpectations(t)
+}
+
+func TestGetPrivateNote_NonMemberDenied(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("OutsiderMSP", "someone", "OUTSIDER")}
+	ec := new(EvidenceContract)
+
+	_, err := ec.GetPrivateNote(ctx, "SITE-001", testCollection)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not a member")
+
+	stub.AssertExpectations(t)
+}
+
+func TestGetPrivateNote_MemberAllowed(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("InspectorMSP", "inspector-01", RoleInspector)}
+	ec := new(EvidenceContract)
+
+	siteID := "SITE-001"
+	note := MiningSitePrivate{SiteID: siteID, Notes: "suspects fled north"}
+	noteJSON, _ := json.Marshal(note)
+
+	stub.On("GetPrivateData", testCollection, siteID).Return(noteJSON, nil).Once()
+
+	result, err := ec.GetPrivateNote(ctx, siteID, testCollection)
+	assert.NoError(t, err)
+	assert.Equal(t, "suspects fled north", result.Notes)
+
+	stub.AssertExpectations(t)
+}
+
+func TestGetPrivateNoteHash_DetectsMismatch(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	ec := new(EvidenceContract)
+
+	siteID := "SITE-001"
+	hashKey := "notehash~site~collection\x00SITE-001\x00" + testCollection + "\x00"
+	hashRecord := PrivateNoteHash{SiteID: siteID, Collection: testCollection, SHA256: "deadbeef"}
+	hashJSON, _ := json.Marshal(hashRecord)
+
+	stub.On("CreateCompositeKey", "notehash~site~collection", []string{siteID, testCollection}).Return(hashKey, nil).Once()
+	stub.On("GetState", hashKey).Return(hashJSON, nil).Once()
+
+	result, err := ec.GetPrivateNoteHash(ctx, siteID, testCollection)
+	assert.NoError(t, err)
+
+	disclosed := MiningSitePrivate{SiteID: siteID, Notes: "a tampered note"}
+	disclosedJSON, _ := json.Marshal(disclosed)
+	disclosedHash := sha256.Sum256(disclosedJSON)
+
+	assert.NotEqual(t, result.SHA256, hex.EncodeToString(disclosedHash[:]))
+
+	stub.AssertExpectations(t)
+}
@@ -0,0 +1,168 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Paginated and Rich Queries
+//
+// GetSitesByStatus issues one GetState round trip per hit and cannot filter
+// by anything but status. QuerySites adds CouchDB rich-query selectors with
+// pagination; GetSitesByStatus takes a pagination cursor of its own so
+// neither has to return a large result set in one call. When the peer's state
+// database does not support rich queries (LevelDB rather than CouchDB),
+// QuerySites falls back to the composite-key path as long as the selector
+// names a status.
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// PagedSites is the paginated result of a sites query.
+type PagedSites struct {
+	Sites        []*MiningSite `json:"sites"`
+	Bookmark     string        `json:"bookmark"`
+	FetchedCount int32         `json:"fetchedCount"`
+}
+
+// statusSelector is the minimal shape QuerySites needs to recognize a
+// status filter when falling back to the composite-key path.
+type statusSelector struct {
+	Selector struct {
+		Status string `json:"status"`
+	} `json:"selector"`
+}
+
+// ---------------------------------------------------------------------------
+// GetSitesByStatus returns sites with the given status, one page at a time,
+// using a composite-key range query.
+// ---------------------------------------------------------------------------
+func (sc *SiteContract) GetSitesByStatus(
+	ctx contractapi.TransactionContextInterface,
+	status string,
+	pageSize int32,
+	bookmark string,
+) (*PagedSites, error) {
+	iterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination("status~id", []string{status}, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sites by status %s: %w", status, err)
+	}
+	defer iterator.Close()
+
+	var sites []*MiningSite
+
+	for iterator.HasNext() {
+		responseRange, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate composite keys: %w", err)
+		}
+
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(responseRange.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key: %w", err)
+		}
+
+		if len(compositeKeyParts) < 2 {
+			continue
+		}
+
+		siteID := compositeKeyParts[1]
+		siteJSON, err := ctx.GetStub().GetState(siteID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read site %s: %w", siteID, err)
+		}
+		if siteJSON == nil {
+			continue
+		}
+
+		var site MiningSite
+		if err := json.Unmarshal(siteJSON, &site); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal site %s: %w", siteID, err)
+		}
+
+		sites = append(sites, &site)
+	}
+
+	return &PagedSites{
+		Sites:        sites,
+		Bookmark:     metadata.GetBookmark(),
+		FetchedCount: metadata.GetFetchedRecordsCount(),
+	}, nil
+}
+
+// ---------------------------------------------------------------------------
+// QuerySites filters sites with a Mongo-style CouchDB selector, e.g.
+// {"selector":{"status":"ACTIVE","region":"Kolwezi","confidenceAI":{"$gte":0.85}}},
+// paginating results with pageSize and bookmark. When the peer's state
+// database does not support rich queries, it falls back to the
+// composite-key status index as long as the selector names a status.
+// ---------------------------------------------------------------------------
+func (sc *SiteContract) QuerySites(
+	ctx contractapi.TransactionContextInterface,
+	selectorJSON string,
+	pageSize int32,
+	bookmark string,
+) (*PagedSites, error) {
+	if strings.TrimSpace(selectorJSON) == "" {
+		return nil, fmt.Errorf("selector must not be empty")
+	}
+
+	iterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(selectorJSON, pageSize, bookmark)
+	if err != nil {
+		if isQueryNotSupported(err) {
+			return sc.fallbackQueryByStatus(ctx, selectorJSON, pageSize, bookmark)
+		}
+		return nil, fmt.Errorf("failed to run rich query: %w", err)
+	}
+	defer iterator.Close()
+
+	var sites []*MiningSite
+
+	for iterator.HasNext() {
+		responseRange, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate query results: %w", err)
+		}
+
+		var site MiningSite
+		if err := json.Unmarshal(responseRange.Value, &site); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal site: %w", err)
+		}
+		sites = append(sites, &site)
+	}
+
+	return &PagedSites{
+		Sites:        sites,
+		Bookmark:     metadata.GetBookmark(),
+		FetchedCount: metadata.GetFetchedRecordsCount(),
+	}, nil
+}
+
+// isQueryNotSupported reports whether err is the error Fabric returns when
+// rich queries are requested against a state database (LevelDB) that
+// doesn't support them. There is no exported sentinel for this, so the
+// error message itself is matched.
+func isQueryNotSupported(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "not supported")
+}
+
+// fallbackQueryByStatus services a rich-query selector via the composite-key
+// status index when CouchDB is unavailable. It only supports selectors that
+// filter on status; anything richer has no LevelDB-compatible equivalent.
+func (sc *SiteContract) fallbackQueryByStatus(
+	ctx contractapi.TransactionContextInterface,
+	selectorJSON string,
+	pageSize int32,
+	bookmark string,
+) (*PagedSites, error) {
+	var selector statusSelector
+	if err := json.Unmarshal([]byte(selectorJSON), &selector); err != nil {
+		return nil, fmt.Errorf("failed to parse selector for fallback query: %w", err)
+	}
+	if selector.Selector.Status == "" {
+		return nil, fmt.Errorf("rich queries are unavailable on this peer and the selector does not filter by status")
+	}
+
+	return sc.GetSitesByStatus(ctx, selector.Selector.Status, pageSize, bookmark)
+}
@@ -0,0 +1,115 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Paginated and Rich Queries Unit Tests
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSitesByStatus_Paginated(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	sc := new(SiteContract)
+
+	site := MiningSite{ID: "SITE-001", Status: "ACTIVE"}
+	siteJSON, _ := json.Marshal(site)
+
+	iterator := &mockStateQueryIterator{results: []*queryresultKV{
+		{Key: "status~id\x00ACTIVE\x00SITE-001\x00", Value: nil},
+	}}
+	metadata := &peer.QueryResponseMetadata{FetchedRecordsCount: 1, Bookmark: "next-page"}
+
+	stub.On("GetStateByPartialCompositeKeyWithPagination", "status~id", []string{"ACTIVE"}, int32(10), "").
+		Return(iterator, metadata, nil).Once()
+	stub.On("SplitCompositeKey", "status~id\x00ACTIVE\x00SITE-001\x00").
+		Return("status~id", []string{"ACTIVE", "SITE-001"}, nil).Once()
+	stub.On("GetState", "SITE-001").Return(siteJSON, nil).Once()
+
+	result, err := sc.GetSitesByStatus(ctx, "ACTIVE", 10, "")
+	assert.NoError(t, err)
+	assert.Len(t, result.Sites, 1)
+	assert.Equal(t, "SITE-001", result.Sites[0].ID)
+	assert.Equal(t, "next-page", result.Bookmark)
+	assert.Equal(t, int32(1), result.FetchedCount)
+
+	stub.AssertExpectations(t)
+}
+
+func TestQuerySites_RichQuery(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	sc := new(SiteContract)
+
+	site := MiningSite{ID: "SITE-001", Status: "ACTIVE", Region: "Kolwezi"}
+	siteJSON, _ := json.Marshal(site)
+
+	selector := `{"selector":{"status":"ACTIVE","region":"Kolwezi"}}`
+	iterator := &mockStateQueryIterator{results: []*queryresultKV{
+		{Key: "SITE-001", Value: siteJSON},
+	}}
+	metadata := &peer.QueryResponseMetadata{FetchedRecordsCount: 1, Bookmark: ""}
+
+	stub.On("GetQueryResultWithPagination", selector, int32(5), "").Return(iterator, metadata, nil).Once()
+
+	result, err := sc.QuerySites(ctx, selector, 5, "")
+	assert.NoError(t, err)
+	assert.Len(t, result.Sites, 1)
+	assert.Equal(t, "Kolwezi", result.Sites[0].Region)
+
+	stub.AssertExpectations(t)
+}
+
+func TestQuerySites_FallsBackWhenNotSupported(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	sc := new(SiteContract)
+
+	site := MiningSite{ID: "SITE-001", Status: "ACTIVE"}
+	siteJSON, _ := json.Marshal(site)
+
+	selector := `{"selector":{"status":"ACTIVE"}}`
+
+	stub.On("GetQueryResultWithPagination", selector, int32(10), "").
+		Return(nil, (*peer.QueryResponseMetadata)(nil), errors.New("GetQueryResult not supported for leveldb")).Once()
+
+	iterator := &mockStateQueryIterator{results: []*queryresultKV{
+		{Key: "status~id\x00ACTIVE\x00SITE-001\x00", Value: nil},
+	}}
+	metadata := &peer.QueryResponseMetadata{FetchedRecordsCount: 1, Bookmark: ""}
+
+	stub.On("GetStateByPartialCompositeKeyWithPagination", "status~id", []string{"ACTIVE"}, int32(10), "").
+		Return(iterator, metadata, nil).Once()
+	stub.On("SplitCompositeKey", "status~id\x00ACTIVE\x00SITE-001\x00").
+		Return("status~id", []string{"ACTIVE", "SITE-001"}, nil).Once()
+	stub.On("GetState", "SITE-001").Return(siteJSON, nil).Once()
+
+	result, err := sc.QuerySites(ctx, selector, 10, "")
+	assert.NoError(t, err)
+	assert.Len(t, result.Sites, 1)
+	assert.Equal(t, "SITE-001", result.Sites[0].ID)
+
+	stub.AssertExpectations(t)
+}
+
+func TestQuerySites_FallbackWithoutStatusFails(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub}
+	sc := new(SiteContract)
+
+	selector := `{"selector":{"region":"Kolwezi"}}`
+
+	stub.On("GetQueryResultWithPagination", selector, int32(10), "").
+		Return(nil, (*peer.QueryResponseMetadata)(nil), errors.New("GetQueryResult not supported for leveldb")).Once()
+
+	_, err := sc.QuerySites(ctx, selector, 10, "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "does not filter by status")
+
+	stub.AssertExpectations(t)
+}
@@ -56,8 +56,12 @@ func (sc *SiteContract) CreateSite(
 	confidenceAI float64,
 	region string,
 	department string,
-	createdBy string,
 ) error {
+	identity, err := requireRole(ctx, RoleAIPipeline)
+	if err != nil {
+		return err
+	}
+
 	// Check whether the site already exists.
 	existing, err := ctx.GetStub().GetState(id)
 	if err != nil {
@@ -80,7 +84,7 @@ func (sc *SiteContract) CreateSite(
 		Region:       region,
 		Department:   department,
 		IPFSCid:      "",
-		CreatedBy:    createdBy,
+		CreatedBy:    identity.MSPID + "/" + identity.CN,
 		UpdatedAt:    now,
 	}
 
@@ -103,7 +107,41 @@ func (sc *SiteContract) CreateSite(
 		return fmt.Errorf("failed to put composite key: %w", err)
 	}
 
-	return nil
+	if err := indexSiteGeohash(ctx, id, geometryWKT); err != nil {
+		return err
+	}
+
+	links, err := FindOverlappingDismantled(ctx, id, geometryWKT)
+	if err != nil {
+		return fmt.Errorf("failed to check for recurrence: %w", err)
+	}
+
+	detectedEntry, err := newEventEntry(EventSiteDetected, SiteDetected{
+		SiteID:       id,
+		SiteCode:     siteCode,
+		Region:       region,
+		Department:   department,
+		AreaHa:       areaHa,
+		ConfidenceAI: confidenceAI,
+	})
+	if err != nil {
+		return err
+	}
+	entries := []eventEntry{detectedEntry}
+
+	for _, link := range links {
+		recurrenceEntry, err := newEventEntry(EventRecurrenceDetected, RecurrenceDetected{
+			OriginalID: link.OriginalID,
+			NewID:      link.NewID,
+			IoU:        link.IoU,
+		})
+		if err != nil {
+			return err
+		}
+		entries = append(entries, recurrenceEntry)
+	}
+
+	return emitSiteEvent(ctx, id, entries...)
 }
 
 // ---------------------------------------------------------------------------
@@ -113,7 +151,6 @@ func (sc *SiteContract) UpdateStatus(
 	ctx contractapi.TransactionContextInterface,
 	id string,
 	newStatus string,
-	updatedBy string,
 ) error {
 	siteJSON, err := ctx.GetStub().GetState(id)
 	if err != nil {
@@ -145,6 +182,55 @@ func (sc *SiteContract) UpdateStatus(
 		return fmt.Errorf("transition from %s to %s is not allowed", site.Status, newStatus)
 	}
 
+	requiredRole, ok := transitionRoleRequirements[site.Status+"->"+newStatus]
+	if !ok {
+		return fmt.Errorf("transition from %s to %s cannot be performed directly by a client", site.Status, newStatus)
+	}
+	identity, err := requireRole(ctx, requiredRole)
+	if err != nil {
+		return err
+	}
+
+	if newStatus == "CONFIRMED" {
+		sealed, err := hasSealedEvidence(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !sealed {
+			return fmt.Errorf("site %s cannot be confirmed without at least one SEALED evidence bundle", id)
+		}
+	}
+
+	previousStatus := site.Status
+	if err := applyStatusTransition(ctx, &site, newStatus); err != nil {
+		return err
+	}
+
+	if err := recordTransition(ctx, id, previousStatus, newStatus, identity); err != nil {
+		return err
+	}
+
+	changedEntry, err := newEventEntry(EventStatusChanged, StatusChanged{
+		From: previousStatus,
+		To:   newStatus,
+		By:   identity.MSPID + "/" + identity.CN,
+	})
+	if err != nil {
+		return err
+	}
+
+	return emitSiteEvent(ctx, id, changedEntry)
+}
+
+// ---------------------------------------------------------------------------
+// applyStatusTransition swaps the status~id composite key and persists the
+// site's new status. It assumes the transition has already been validated
+// and is shared by UpdateStatus and by the automatic DISMANTLED -> RECURRED
+// transition triggered by recurrence detection.
+// ---------------------------------------------------------------------------
+func applyStatusTransition(ctx contractapi.TransactionContextInterface, site *MiningSite, newStatus string) error {
+	id := site.ID
+
 	// Remove old composite key.
 	oldCompositeKey, err := ctx.GetStub().CreateCompositeKey("status~id", []string{site.Status, id})
 	if err != nil {
@@ -244,53 +330,4 @@ func (sc *SiteContract) GetSiteHistory(
 	return history, nil
 }
 
-// ---------------------------------------------------------------------------
-// GetSitesByStatus returns all sites with the given status using a composite
-// key range query.
-// ---------------------------------------------------------------------------
-func (sc *SiteContract) GetSitesByStatus(
-	ctx contractapi.TransactionContextInterface,
-	status string,
-) ([]*MiningSite, error) {
-	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("status~id", []string{status})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get sites by status %s: %w", status, err)
-	}
-	defer iterator.Close()
-
-	var sites []*MiningSite
-
-	for iterator.HasNext() {
-		responseRange, err := iterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate composite keys: %w", err)
-		}
-
-		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(responseRange.Key)
-		if err != nil {
-			return nil, fmt.Errorf("failed to split composite key: %w", err)
-		}
-
-		if len(compositeKeyParts) < 2 {
-			continue
-		}
-
-		siteID := compositeKeyParts[1]
-		siteJSON, err := ctx.GetStub().GetState(siteID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read site %s: %w", siteID, err)
-		}
-		if siteJSON == nil {
-			continue
-		}
-
-		var site MiningSite
-		if err := json.Unmarshal(siteJSON, &site); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal site %s: %w", siteID, err)
-		}
-
-		sites = append(sites, &site)
-	}
-
-	return sites, nil
-}
+// GetSitesByStatus and QuerySites (paginated rich queries) live in query.go.
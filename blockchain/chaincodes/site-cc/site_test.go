@@ -4,12 +4,16 @@
 package main
 
 import (
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -66,37 +70,133 @@ func (m *MockChaincodeStub) SplitCompositeKey(compositeKey string) (string, []st
 	return args.String(0), args.Get(1).([]string), args.Error(2)
 }
 
+func (m *MockChaincodeStub) GetTxID() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockChaincodeStub) SetEvent(name string, payload []byte) error {
+	args := m.Called(name, payload)
+	return args.Error(0)
+}
+
+func (m *MockChaincodeStub) GetPrivateData(collection, key string) ([]byte, error) {
+	args := m.Called(collection, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockChaincodeStub) PutPrivateData(collection, key string, value []byte) error {
+	args := m.Called(collection, key, value)
+	return args.Error(0)
+}
+
+func (m *MockChaincodeStub) GetTransient() (map[string][]byte, error) {
+	args := m.Called()
+	return args.Get(0).(map[string][]byte), args.Error(1)
+}
+
+func (m *MockChaincodeStub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	args := m.Called(objectType, keys, pageSize, bookmark)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(*peer.QueryResponseMetadata), args.Error(2)
+	}
+	return args.Get(0).(shim.StateQueryIteratorInterface), args.Get(1).(*peer.QueryResponseMetadata), args.Error(2)
+}
+
+func (m *MockChaincodeStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	args := m.Called(query, pageSize, bookmark)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(*peer.QueryResponseMetadata), args.Error(2)
+	}
+	return args.Get(0).(shim.StateQueryIteratorInterface), args.Get(1).(*peer.QueryResponseMetadata), args.Error(2)
+}
+
+// ---------------------------------------------------------------------------
+// Mock ClientIdentity
+// ---------------------------------------------------------------------------
+type MockClientIdentity struct {
+	mspID string
+	cert  *x509.Certificate
+}
+
+func newMockClientIdentity(mspID, cn, ou string) *MockClientIdentity {
+	return &MockClientIdentity{
+		mspID: mspID,
+		cert: &x509.Certificate{
+			Raw: []byte("test-cert:" + mspID + ":" + cn + ":" + ou),
+			Subject: pkix.Name{
+				CommonName:         cn,
+				OrganizationalUnit: []string{ou},
+			},
+		},
+	}
+}
+
+func (m *MockClientIdentity) GetID() (string, error) {
+	return m.mspID + "/" + m.cert.Subject.CommonName, nil
+}
+
+func (m *MockClientIdentity) GetMSPID() (string, error) {
+	return m.mspID, nil
+}
+
+func (m *MockClientIdentity) GetAttributeValue(attrName string) (string, bool, error) {
+	return "", false, nil
+}
+
+func (m *MockClientIdentity) AssertAttributeValue(attrName, attrValue string) error {
+	return nil
+}
+
+func (m *MockClientIdentity) GetX509Certificate() (*x509.Certificate, error) {
+	return m.cert, nil
+}
+
 // ---------------------------------------------------------------------------
 // Mock TransactionContext
 // ---------------------------------------------------------------------------
 type MockTransactionContext struct {
 	contractapi.TransactionContext
-	stub *MockChaincodeStub
+	stub           *MockChaincodeStub
+	clientIdentity cid.ClientIdentity
 }
 
 func (m *MockTransactionContext) GetStub() shim.ChaincodeStubInterface {
 	return m.stub
 }
 
+func (m *MockTransactionContext) GetClientIdentity() cid.ClientIdentity {
+	return m.clientIdentity
+}
+
 // ---------------------------------------------------------------------------
 // Tests
 // ---------------------------------------------------------------------------
 
 func TestCreateSite(t *testing.T) {
 	stub := NewMockChaincodeStub()
-	ctx := &MockTransactionContext{stub: stub}
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("Org1MSP", "ai-pipeline-01", RoleAIPipeline)}
 	sc := new(SiteContract)
 
 	id := "SITE-001"
+	geometryWKT := "POLYGON((29.5 -10.5, 29.6 -10.5, 29.6 -10.6, 29.5 -10.6, 29.5 -10.5))"
 	compositeKey := "status~id\x00DETECTED\x00SITE-001\x00"
+	geohashKey := "geohash~id\x00kwj5br\x00SITE-001\x00"
 
 	// Site does not exist yet.
 	stub.On("GetState", id).Return(nil, nil).Once()
 	stub.On("PutState", id, mock.AnythingOfType("[]uint8")).Return(nil).Once()
 	stub.On("CreateCompositeKey", "status~id", []string{"DETECTED", id}).Return(compositeKey, nil).Once()
 	stub.On("PutState", compositeKey, []byte{0x00}).Return(nil).Once()
+	stub.On("CreateCompositeKey", "geohash~id", []string{"kwj5br", id}).Return(geohashKey, nil).Once()
+	stub.On("PutState", geohashKey, []byte{0x00}).Return(nil).Once()
+	stub.On("GetStateByPartialCompositeKey", "geohash~id", []string{"kwj5br"}).Return(&mockStateQueryIterator{}, nil).Once()
+	stub.On("SetEvent", siteEventName, mock.AnythingOfType("[]uint8")).Return(nil).Once()
 
-	err := sc.CreateSite(ctx, id, "MCK-2024-0001", "POLYGON((...))", 12.5, 0.92, "Kolwezi", "Lualaba", "ai-pipeline")
+	err := sc.CreateSite(ctx, id, "MCK-2024-0001", geometryWKT, 12.5, 0.92, "Kolwezi", "Lualaba")
 	assert.NoError(t, err)
 
 	// Verify the site was stored.
@@ -113,14 +213,26 @@ func TestCreateSite(t *testing.T) {
 	assert.Equal(t, 0.92, site.ConfidenceAI)
 	assert.Equal(t, "Kolwezi", site.Region)
 	assert.Equal(t, "Lualaba", site.Department)
-	assert.Equal(t, "ai-pipeline", site.CreatedBy)
+	assert.Equal(t, "Org1MSP/ai-pipeline-01", site.CreatedBy)
+
+	stub.AssertExpectations(t)
+}
+
+func TestCreateSite_WrongRole(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("Org1MSP", "inspector-01", RoleInspector)}
+	sc := new(SiteContract)
+
+	err := sc.CreateSite(ctx, "SITE-001", "MCK-2024-0001", "POLYGON((...))", 12.5, 0.92, "Kolwezi", "Lualaba")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not authorized")
 
 	stub.AssertExpectations(t)
 }
 
 func TestCreateSite_AlreadyExists(t *testing.T) {
 	stub := NewMockChaincodeStub()
-	ctx := &MockTransactionContext{stub: stub}
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("Org1MSP", "ai-pipeline-01", RoleAIPipeline)}
 	sc := new(SiteContract)
 
 	id := "SITE-001"
@@ -129,7 +241,7 @@ func TestCreateSite_AlreadyExists(t *testing.T) {
 
 	stub.On("GetState", id).Return(existingJSON, nil).Once()
 
-	err := sc.CreateSite(ctx, id, "MCK-2024-0001", "POLYGON((...))", 12.5, 0.92, "Kolwezi", "Lualaba", "ai-pipeline")
+	err := sc.CreateSite(ctx, id, "MCK-2024-0001", "POLYGON((...))", 12.5, 0.92, "Kolwezi", "Lualaba")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "already exists")
 
@@ -138,12 +250,13 @@ func TestCreateSite_AlreadyExists(t *testing.T) {
 
 func TestUpdateStatus_ValidTransition(t *testing.T) {
 	stub := NewMockChaincodeStub()
-	ctx := &MockTransactionContext{stub: stub}
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("Org1MSP", "inspector-01", RoleInspector)}
 	sc := new(SiteContract)
 
 	id := "SITE-001"
 	oldCompositeKey := "status~id\x00DETECTED\x00SITE-001\x00"
 	newCompositeKey := "status~id\x00UNDER_REVIEW\x00SITE-001\x00"
+	transitionKey := "transition~site~ts\x00SITE-001\x002024-01-15T10:00:00Z\x00"
 
 	site := MiningSite{
 		ID:           id,
@@ -155,7 +268,7 @@ func TestUpdateStatus_ValidTransition(t *testing.T) {
 		Status:       "DETECTED",
 		Region:       "Kolwezi",
 		Department:   "Lualaba",
-		CreatedBy:    "ai-pipeline",
+		CreatedBy:    "Org1MSP/ai-pipeline-01",
 		UpdatedAt:    "2024-01-15T10:00:00Z",
 	}
 	siteJSON, _ := json.Marshal(site)
@@ -166,8 +279,12 @@ func TestUpdateStatus_ValidTransition(t *testing.T) {
 	stub.On("PutState", id, mock.AnythingOfType("[]uint8")).Return(nil).Once()
 	stub.On("CreateCompositeKey", "status~id", []string{"UNDER_REVIEW", id}).Return(newCompositeKey, nil).Once()
 	stub.On("PutState", newCompositeKey, []byte{0x00}).Return(nil).Once()
+	stub.On("GetTxID").Return("tx-1").Once()
+	stub.On("CreateCompositeKey", "transition~site~ts", mock.AnythingOfType("[]string")).Return(transitionKey, nil).Once()
+	stub.On("PutState", transitionKey, mock.AnythingOfType("[]uint8")).Return(nil).Once()
+	stub.On("SetEvent", siteEventName, mock.AnythingOfType("[]uint8")).Return(nil).Once()
 
-	err := sc.UpdateStatus(ctx, id, "UNDER_REVIEW", "inspector-01")
+	err := sc.UpdateStatus(ctx, id, "UNDER_REVIEW")
 	assert.NoError(t, err)
 
 	// Verify the status was updated.
@@ -179,6 +296,30 @@ func TestUpdateStatus_ValidTransition(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "UNDER_REVIEW", updatedSite.Status)
 
+	var transition StatusTransition
+	assert.NoError(t, json.Unmarshal(stub.state[transitionKey], &transition))
+	assert.Equal(t, "DETECTED", transition.From)
+	assert.Equal(t, "UNDER_REVIEW", transition.To)
+	assert.Equal(t, "Org1MSP", transition.MSPID)
+
+	stub.AssertExpectations(t)
+}
+
+func TestUpdateStatus_WrongRole(t *testing.T) {
+	stub := NewMockChaincodeStub()
+	ctx := &MockTransactionContext{stub: stub, clientIdentity: newMockClientIdentity("Org1MSP", "ai-pipeline-01", RoleAIPipeline)}
+	sc := new(SiteContract)
+
+	id := "SITE-001"
+	site := MiningSite{ID: id, Status: "DETECTED"}
+	siteJSON, _ := json.Marshal(site)
+
+	stub.On("GetState", id).Return(siteJSON, nil).Once()
+
+	err := sc.UpdateStatus(ctx, id, "UNDER_REVIEW")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not authorized")
+
 	stub.AssertExpectations(t)
 }
 
@@ -198,7 +339,7 @@ func TestUpdateStatus_InvalidTransition(t *testing.T) {
 	// Try to go directly from DETECTED to ACTIVE (skipping UNDER_REVIEW and CONFIRMED).
 	stub.On("GetState", id).Return(siteJSON, nil).Once()
 
-	err := sc.UpdateStatus(ctx, id, "ACTIVE", "inspector-01")
+	err := sc.UpdateStatus(ctx, id, "ACTIVE")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not allowed")
 
@@ -232,7 +373,7 @@ func TestUpdateStatus_InvalidTransition_AllPaths(t *testing.T) {
 
 			stub.On("GetState", id).Return(siteJSON, nil).Once()
 
-			err := sc.UpdateStatus(ctx, id, tt.toStatus, "inspector-01")
+			err := sc.UpdateStatus(ctx, id, tt.toStatus)
 			assert.Error(t, err)
 			assert.Contains(t, err.Error(), tt.expectError)
 
@@ -248,7 +389,7 @@ func TestUpdateStatus_SiteNotFound(t *testing.T) {
 
 	stub.On("GetState", "NONEXISTENT").Return(nil, nil).Once()
 
-	err := sc.UpdateStatus(ctx, "NONEXISTENT", "UNDER_REVIEW", "inspector-01")
+	err := sc.UpdateStatus(ctx, "NONEXISTENT", "UNDER_REVIEW")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "does not exist")
 
@@ -271,7 +412,7 @@ func TestGetSite(t *testing.T) {
 		Status:       "DETECTED",
 		Region:       "Kolwezi",
 		Department:   "Lualaba",
-		CreatedBy:    "ai-pipeline",
+		CreatedBy:    "Org1MSP/ai-pipeline-01",
 		UpdatedAt:    "2024-01-15T10:00:00Z",
 	}
 	siteJSON, _ := json.Marshal(expectedSite)
@@ -288,7 +429,7 @@ func TestGetSite(t *testing.T) {
 	assert.Equal(t, 0.92, site.ConfidenceAI)
 	assert.Equal(t, "Kolwezi", site.Region)
 	assert.Equal(t, "Lualaba", site.Department)
-	assert.Equal(t, "ai-pipeline", site.CreatedBy)
+	assert.Equal(t, "Org1MSP/ai-pipeline-01", site.CreatedBy)
 
 	stub.AssertExpectations(t)
 }
@@ -0,0 +1,109 @@
+// ---------------------------------------------------------------------------
+// Ge O'Miner  --  Status Transition Ledger
+//
+// Records every site status transition as an immutable entry carrying the
+// real signing identity that performed it, rather than a self-declared
+// "updatedBy" string.
+// ---------------------------------------------------------------------------
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// systemIdentity is recorded on transitions triggered by chaincode logic
+// itself (e.g. automatic recurrence detection) rather than by a client.
+var systemIdentity = &Identity{MSPID: "SYSTEM", CN: "recurrence-detector", OU: "SYSTEM"}
+
+// transitionRoleRequirements maps a "from->to" status transition to the
+// role required to perform it.
+var transitionRoleRequirements = map[string]string{
+	"DETECTED->UNDER_REVIEW":  RoleInspector,
+	"UNDER_REVIEW->CONFIRMED": RoleSupervisor,
+	"CONFIRMED->ACTIVE":       RoleSupervisor,
+	"CONFIRMED->ESCALATED":    RoleMinistry,
+	"ACTIVE->DISMANTLED":      RoleInspector,
+}
+
+// StatusTransition is an immutable record of a site status change and the
+// identity that signed the transaction which performed it.
+type StatusTransition struct {
+	SiteID     string `json:"siteId"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	MSPID      string `json:"mspId"`
+	CN         string `json:"cn"`
+	CertSHA256 string `json:"certSha256"`
+	TxID       string `json:"txId"`
+	Timestamp  string `json:"timestamp"`
+}
+
+// recordTransition persists an immutable StatusTransition entry under a
+// transition~site~ts composite key.
+func recordTransition(ctx contractapi.TransactionContextInterface, siteID, from, to string, by *Identity) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	transition := StatusTransition{
+		SiteID:     siteID,
+		From:       from,
+		To:         to,
+		MSPID:      by.MSPID,
+		CN:         by.CN,
+		CertSHA256: by.CertSHA256,
+		TxID:       ctx.GetStub().GetTxID(),
+		Timestamp:  now,
+	}
+
+	transitionJSON, err := json.Marshal(transition)
+	if err != nil {
+		return fmt.Errorf("failed to marshal status transition: %w", err)
+	}
+
+	// The TxID is appended to keep the key unique even when two transitions
+	// land in the same wall-clock second, which would otherwise silently
+	// overwrite one another via PutState and drop an entry from what is
+	// documented as an immutable audit trail.
+	key, err := ctx.GetStub().CreateCompositeKey("transition~site~ts", []string{siteID, now, transition.TxID})
+	if err != nil {
+		return fmt.Errorf("failed to create transition key: %w", err)
+	}
+	if err := ctx.GetStub().PutState(key, transitionJSON); err != nil {
+		return fmt.Errorf("failed to put status transition: %w", err)
+	}
+
+	return nil
+}
+
+// GetSiteTransitions returns the ordered, signed transition history of a
+// site, as recorded by recordTransition.
+func (sc *SiteContract) GetSiteTransitions(
+	ctx contractapi.TransactionContextInterface,
+	siteID string,
+) ([]*StatusTransition, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("transition~site~ts", []string{siteID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transitions for site %s: %w", siteID, err)
+	}
+	defer iterator.Close()
+
+	var transitions []*StatusTransition
+
+	for iterator.HasNext() {
+		responseRange, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate transitions: %w", err)
+		}
+
+		var transition StatusTransition
+		if err := json.Unmarshal(responseRange.Value, &transition); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal status transition: %w", err)
+		}
+		transitions = append(transitions, &transition)
+	}
+
+	return transitions, nil
+}